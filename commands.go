@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/averche/docker-compose-graph/internal/config"
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+// textFormats are the formats of the commands printing reports rather than graphs
+var textFormats = []string{"text"}
+
+// legendFormats are the graph formats which draw a legend
+var legendFormats = []string{"dot", "drawio", "html", "svg"}
+
+var graphCommand = command{
+	name:    "graph",
+	args:    "<compose-file|dir>...",
+	summary: "render the graph of services, volumes & dependencies (format inferred from -o, dot by default)",
+	// dot is the default format, the others are listed alphabetically
+	formats: append([]string{"dot"}, slices.DeleteFunc(composegraph.Formats(), func(f string) bool { return f == "dot" })...),
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		reverse := fs.Bool("reverse", false, "tree format: print the dependents of each service instead of its dependencies")
+		mermaid := fs.Bool("mermaid", false, "markdown format: append a mermaid flowchart to the report")
+		edgeLabels := fs.Bool("edge-labels", false, "dot format: label the mounts with their targets & the dependencies with their conditions")
+		heatmap := fs.String("heatmap", "", "dot format: color the services by the given `metric`: fan-in or fan-out")
+		legend := fs.String("legend", "", "placement of the legend: inline, none or only (default inline)")
+		legendOutput := fs.String("legend-output", "", "write the legend to the given file (in the output format) rather than next to the graph")
+		watch := fs.Bool("watch", false, "render to the output file again whenever the inputs change, until interrupted")
+		var focus stringList
+		fs.Var(&focus, "focus", "glob `pattern` of the services & volumes to keep along with their dependencies & dependents; may be repeated")
+		layout := layoutFlags(fs)
+		annotations := annotationFlags(fs)
+
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no compose files given")
+			}
+
+			c.focus = focus
+
+			var legendErr error
+
+			// only the legend flags are rejected by the formats without a legend, not the config
+			fs.Visit(func(f *flag.Flag) {
+				if (f.Name == "legend" || f.Name == "legend-output") && !slices.Contains(legendFormats, c.format) {
+					legendErr = usageError("the %s format has no legend (available: %s)", c.format, strings.Join(legendFormats, ", "))
+				}
+			})
+			if legendErr != nil {
+				return legendErr
+			}
+
+			c.legend = *legend
+			if c.legend == "" && *legendOutput == "" && slices.Contains(legendFormats, c.format) {
+				c.legend = c.config.Legend
+			}
+
+			if *legendOutput != "" && c.legend != "" && c.legend != composegraph.LegendInline.String() {
+				return usageError("-legend-output cannot be combined with -legend %s", c.legend)
+			}
+
+			options := composegraph.RenderOptions{
+				Color:       c.color(),
+				Reverse:     *reverse,
+				Mermaid:     *mermaid,
+				EdgeLabels:  *edgeLabels,
+				Layout:      layout(c.config.Layout),
+				Annotations: annotations(c.config.Annotations),
+			}
+
+			if *heatmap != "" {
+				if c.format != "dot" {
+					return usageError("-heatmap is only supported by the dot format")
+				}
+
+				metric, err := composegraph.ParseMetric(*heatmap)
+				if err != nil {
+					return usageError("%v", err)
+				}
+				options.Heatmap = metric
+			}
+
+			if err := options.Layout.Validate(); err != nil {
+				return usageError("%v", err)
+			}
+
+			if err := options.Annotations.Validate(); err != nil {
+				return usageError("%v", err)
+			}
+
+			render := func() error {
+				g, err := c.load(args)
+				if err != nil {
+					return err
+				}
+
+				if *legendOutput != "" {
+					legend := g
+					legend.Legend = composegraph.LegendOnly
+
+					err := c.writeTo(*legendOutput, func(w io.Writer) error {
+						return composegraph.Render(w, legend, c.format, options)
+					})
+					if err != nil {
+						return err
+					}
+
+					g.Legend = composegraph.LegendHidden
+				}
+
+				return c.write(func(w io.Writer) error {
+					return composegraph.Render(w, g, c.format, options)
+				})
+			}
+
+			if !*watch {
+				return render()
+			}
+
+			switch {
+			case c.output == "":
+				return usageError("-watch requires an output file (-o)")
+			case c.check:
+				return usageError("-watch cannot be combined with -check")
+			case slices.Contains(args, "-"):
+				return usageError("stdin ('-') cannot be watched")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			return c.watch(ctx, args, watchInterval, func() error {
+				if err := render(); err != nil {
+					return err
+				}
+				fmt.Fprintf(c.stderr, "%s rendered %s\n", time.Now().Format(time.TimeOnly), c.output)
+				return nil
+			})
+		}
+	},
+}
+
+var validateCommand = command{
+	name:    "validate",
+	args:    "<compose-file|dir>...",
+	summary: "check for dependencies on undefined services, mounts of undeclared volumes & dependency cycles",
+	formats: textFormats,
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no compose files given")
+			}
+
+			g, err := c.load(args)
+			if err != nil {
+				return err
+			}
+
+			problems := composegraph.Validate(g)
+
+			err = c.write(func(w io.Writer) error {
+				for _, p := range problems {
+					if _, err := fmt.Fprintf(w, "%s: %s: %s\n", g.Groups[p.Node.Group].Label, p.Node.Name, p.Message); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(problems) != 0 {
+				return failure(fmt.Errorf("found %d problem(s)", len(problems)))
+			}
+
+			return nil
+		}
+	},
+}
+
+var orderCommand = command{
+	name:    "order",
+	args:    "<compose-file|dir>...",
+	summary: "print the stages in which the services would be started, dependencies first",
+	formats: textFormats,
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no compose files given")
+			}
+
+			g, err := c.load(args)
+			if err != nil {
+				return err
+			}
+
+			stages, err := composegraph.StartupOrder(g)
+			if err != nil {
+				return failure(err)
+			}
+
+			return c.write(func(w io.Writer) error {
+				for i, stage := range stages {
+					names := make([]string, 0, len(stage))
+					for _, id := range stage {
+						names = append(names, displayName(g, id))
+					}
+
+					if _, err := fmt.Fprintf(w, "%d. %s\n", i+1, strings.Join(names, ", ")); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	},
+}
+
+var diffCommand = command{
+	name:    "diff",
+	args:    "<before> <after> | <before>... -- <after>... | -git <rev>[..<rev>] <path>...",
+	summary: "print the services, volumes & edges added, removed or modified between two versions of a project, or render them as a combined graph highlighting the changes",
+	// the summary is the default format, the graph formats render the combined graph
+	formats: append(slices.Clone(textFormats), graphCommand.formats...),
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		revisions := fs.String("git", "", "compare the paths as of the given git revision with the working tree, or as of two revisions ('<before>..<after>')")
+
+		return func(c *cli, args []string) error {
+			before, after, err := c.loadVersions(*revisions, args)
+			if err != nil {
+				return err
+			}
+
+			changes := composegraph.Diff(before, after)
+
+			summary := func(w io.Writer) error {
+				for _, change := range changes {
+					if _, err := fmt.Fprintln(w, change); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if slices.Contains(textFormats, c.format) {
+				err = c.write(summary)
+			} else {
+				combined := composegraph.DiffGraph(before, after)
+
+				err = c.write(func(w io.Writer) error {
+					return composegraph.Render(w, combined, c.format, composegraph.RenderOptions{Color: c.color()})
+				})
+				if err == nil {
+					// the summary accompanies the graph on stderr (e.g. in the logs of a CI job)
+					err = summary(c.stderr)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			// like diff(1), differences are reported through the exit code
+			if len(changes) != 0 {
+				return failure(nil)
+			}
+
+			return nil
+		}
+	},
+}
+
+// loadVersions loads the two versions of the project to compare: two compose files, two sets of
+// inputs separated by '--', or the given paths as of one git revision (against the working tree)
+// or two ('<before>..<after>')
+func (c *cli) loadVersions(revisions string, args []string) (composegraph.Graph, composegraph.Graph, error) {
+	var none composegraph.Graph
+
+	if revisions != "" {
+		if len(args) == 0 || slices.Contains(args, "--") {
+			return none, none, usageError("-git expects the paths to compare, without '--'")
+		}
+
+		from, to, _ := strings.Cut(revisions, "..")
+		if from == "" {
+			return none, none, usageError("invalid revisions '%s' (expected <rev> or <rev>..<rev>)", revisions)
+		}
+
+		before, err := c.loadRevision(from, args)
+		if err != nil {
+			return none, none, err
+		}
+
+		var after composegraph.Graph
+		if to == "" {
+			after, err = c.load(args)
+		} else {
+			after, err = c.loadRevision(to, args)
+		}
+
+		return before, after, err
+	}
+
+	beforeArgs, afterArgs, separated := cutArgs(args, "--")
+
+	switch {
+	case separated && (len(beforeArgs) == 0 || len(afterArgs) == 0):
+		return none, none, usageError("expected compose files on both sides of '--'")
+	case !separated && len(args) != 2:
+		return none, none, usageError("expected 2 compose files, got %d", len(args))
+	case !separated:
+		beforeArgs, afterArgs = args[:1], args[1:]
+	}
+
+	before, err := c.load(beforeArgs)
+	if err != nil {
+		return none, none, err
+	}
+
+	after, err := c.load(afterArgs)
+
+	return before, after, err
+}
+
+// cutArgs slices the arguments around the first occurrence of the separator
+func cutArgs(args []string, separator string) ([]string, []string, bool) {
+	i := slices.Index(args, separator)
+	if i == -1 {
+		return args, nil, false
+	}
+	return args[:i], args[i+1:], true
+}
+
+var statsCommand = command{
+	name:    "stats",
+	args:    "<compose-file|dir>...",
+	summary: "print the metrics of the graph: counts, longest dependency chain, orphans, cycles & the fan-in/fan-out of each service",
+	formats: append(slices.Clone(textFormats), "json"),
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no compose files given")
+			}
+
+			g, err := c.load(args)
+			if err != nil {
+				return err
+			}
+
+			s := composegraph.ComputeStats(g)
+
+			return c.write(func(w io.Writer) error {
+				if c.format == "json" {
+					return printStatsJSON(w, g, s)
+				}
+				return printStats(w, g, s)
+			})
+		}
+	},
+}
+
+// layoutFlags registers the flags of the dot layout; the returned function applies the flags which
+// are set explicitly over the defaults from the config
+func layoutFlags(fs *flag.FlagSet) func(defaults config.Layout) composegraph.DOTLayout {
+	var flags composegraph.DOTLayout
+
+	fs.StringVar(&flags.RankDir, "rankdir", "", "dot format: direction of the ranks: TB, LR, BT or RL (default TB)")
+	fs.Float64Var(&flags.NodeSep, "nodesep", 0, "dot format: minimum space between the nodes of a rank, in inches")
+	fs.Float64Var(&flags.RankSep, "ranksep", 0, "dot format: minimum space between the ranks, in inches")
+	fs.StringVar(&flags.Splines, "splines", "", "dot format: edge routing: spline, line, polyline, ortho, curved or none")
+	fs.BoolVar(&flags.Concentrate, "concentrate", false, "dot format: merge the edges running in parallel")
+	fs.StringVar(&flags.FontName, "fontname", "", "dot format: font of the graph, nodes & edges (default arial)")
+	fs.Float64Var(&flags.FontSize, "fontsize", 0, "dot format: font size of the graph, nodes & edges, in points")
+	fs.BoolVar(&flags.VolumesSink, "volumes-sink", false, "dot format: pin the volumes to the bottom rank of their cluster")
+
+	return func(defaults config.Layout) composegraph.DOTLayout {
+		layout := composegraph.DOTLayout(defaults)
+
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "rankdir":
+				layout.RankDir = flags.RankDir
+			case "nodesep":
+				layout.NodeSep = flags.NodeSep
+			case "ranksep":
+				layout.RankSep = flags.RankSep
+			case "splines":
+				layout.Splines = flags.Splines
+			case "concentrate":
+				layout.Concentrate = flags.Concentrate
+			case "fontname":
+				layout.FontName = flags.FontName
+			case "fontsize":
+				layout.FontSize = flags.FontSize
+			case "volumes-sink":
+				layout.VolumesSink = flags.VolumesSink
+			}
+		})
+
+		return layout
+	}
+}
+
+// annotationFlags registers the flags of the tooltips & hyperlinks; the returned function applies the
+// flags which are set explicitly over the defaults from the config
+func annotationFlags(fs *flag.FlagSet) func(defaults config.Annotations) composegraph.Annotations {
+	var flags composegraph.Annotations
+
+	fs.BoolVar(&flags.Tooltips, "tooltips", false, "dot & svg formats: describe the image, ports & mounts of the nodes & the edges in tooltips")
+	fs.StringVar(&flags.URL, "url-template", "", "dot & svg formats: link the services to the url rendered from the given template (e.g. 'https://git.example/{{.File}}#L{{.Line}}')")
+	fs.StringVar(&flags.Target, "url-target", "", "dot & svg formats: window in which the links are opened (e.g. _blank)")
+
+	return func(defaults config.Annotations) composegraph.Annotations {
+		annotations := composegraph.Annotations(defaults)
+
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "tooltips":
+				annotations.Tooltips = flags.Tooltips
+			case "url-template":
+				annotations.URL = flags.URL
+			case "url-target":
+				annotations.Target = flags.Target
+			}
+		})
+
+		return annotations
+	}
+}
+
+// displayName qualifies the node name with its group label when the graph has multiple groups
+func displayName(g composegraph.Graph, id composegraph.NodeID) string {
+	if len(g.Groups) < 2 {
+		return id.Name
+	}
+	return g.Groups[id.Group].Label + ":" + id.Name
+}
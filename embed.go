@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var embedCommand = command{
+	name:    "embed",
+	args:    "<markdown-file>...",
+	summary: "regenerate the graphs embedded in markdown files between compose-graph markers",
+	formats: textFormats,
+	inPlace: true,
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no markdown files given")
+			}
+
+			for _, name := range args {
+				if err := c.embed(name); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	},
+}
+
+var (
+	// embedStart matches the marker opening a block, e.g. <!-- compose-graph:start args="-format mermaid compose.yaml" -->
+	embedStart = regexp.MustCompile(`^\s*<!--\s*compose-graph:start(?:\s+args="([^"]*)")?\s*-->\s*$`)
+
+	// embedEnd matches the marker closing a block
+	embedEnd = regexp.MustCompile(`^\s*<!--\s*compose-graph:end\s*-->\s*$`)
+
+	// embedFence matches the fence of a code block (``` or ~~~, at least 3 long) & its info string;
+	// the markers within code blocks are documentation rather than blocks to regenerate
+	embedFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+)
+
+// embedFences are the languages of the code blocks embedding the text formats; the markdown format
+// is embedded as is while the others must be written to a file (-o) which is linked as an image
+var embedFences = map[string]string{
+	"dot":     "dot",
+	"mermaid": "mermaid",
+	"tree":    "text",
+}
+
+// embed regenerates the blocks between the markers of the markdown file & rewrites it if they changed
+// (in check mode, the differences are printed instead)
+func (c *cli) embed(name string) error {
+	original, err := os.ReadFile(name)
+	if err != nil {
+		return parseError(fmt.Errorf("could not read '%s': %w", name, err))
+	}
+
+	newline := "\n"
+	if bytes.Contains(original, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+
+	lines := strings.SplitAfter(string(original), "\n")
+
+	var (
+		b     strings.Builder
+		fence string
+	)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			b.WriteString(lines[i])
+			continue
+		}
+
+		if fence = opensFence(line); fence != "" {
+			b.WriteString(lines[i])
+			continue
+		}
+
+		if embedEnd.MatchString(line) {
+			return parseError(fmt.Errorf("%s:%d: end marker without a start marker", name, i+1))
+		}
+
+		b.WriteString(lines[i])
+
+		m := embedStart.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		start := i
+
+		for i++; i < len(lines) && !embedEnd.MatchString(strings.TrimRight(lines[i], "\r\n")); i++ {
+			if embedStart.MatchString(strings.TrimRight(lines[i], "\r\n")) {
+				return parseError(fmt.Errorf("%s:%d: nested start marker", name, i+1))
+			}
+		}
+		if i == len(lines) {
+			return parseError(fmt.Errorf("%s:%d: start marker without an end marker", name, start+1))
+		}
+
+		content, err := c.embedBlock(filepath.Dir(name), m[1])
+		if err != nil {
+			return embedError(fmt.Sprintf("%s:%d", name, start+1), err)
+		}
+
+		b.WriteString(strings.ReplaceAll(content, "\n", newline))
+		b.WriteString(lines[i])
+	}
+
+	updated := []byte(b.String())
+
+	if !c.check && bytes.Equal(updated, original) {
+		return nil
+	}
+
+	return c.writeTo(name, func(w io.Writer) error {
+		_, err := w.Write(updated)
+		return err
+	})
+}
+
+// opensFence returns the fence of the code block opened by the line, if any (the info string of a
+// backtick fence cannot contain backticks)
+func opensFence(line string) string {
+	m := embedFence.FindStringSubmatch(line)
+	if m == nil || (m[1][0] == '`' && strings.Contains(m[2], "`")) {
+		return ""
+	}
+	return m[1]
+}
+
+// closesFence reports whether the line closes the code block opened by the given fence: a fence of
+// the same character, at least as long & without an info string
+func closesFence(line, fence string) bool {
+	m := embedFence.FindStringSubmatch(line)
+	return m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(m[2]) == ""
+}
+
+// embedBlock renders the block of the given marker args with the graph command, resolving the paths
+// relative to the directory of the markdown file; the output file (if any) is linked as an image
+func (c *cli) embedBlock(dir, args string) (string, error) {
+	fields, err := splitArgs(args)
+	if err != nil {
+		return "", usageError("%v", err)
+	}
+
+	var b bytes.Buffer
+
+	block := cli{stdin: strings.NewReader(""), stdout: &b, stderr: c.stderr}
+
+	fs, runner := block.flags(graphCommand)
+	fs.SetOutput(io.Discard)
+
+	if err := fs.Parse(fields); err != nil {
+		return "", usageError("invalid marker args: %v", err)
+	}
+
+	if fs.Lookup("watch").Value.String() == "true" {
+		return "", usageError("-watch cannot be embedded")
+	}
+
+	link := block.output
+
+	var resolveErr error
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "o", "output", "config", "legend-output":
+			resolveErr = errors.Join(resolveErr, fs.Set(f.Name, resolvePath(dir, f.Value.String())))
+		}
+	})
+	if resolveErr != nil {
+		return "", usageError("invalid marker args: %v", resolveErr)
+	}
+
+	inputs := fs.Args()
+	for i, input := range inputs {
+		if input == "-" {
+			return "", usageError("stdin ('-') cannot be embedded")
+		}
+		inputs[i] = resolvePath(dir, input)
+	}
+
+	// only the output files are checked, the blocks are compared along with the markdown file
+	block.check = c.check && block.output != ""
+
+	if err := block.configure(graphCommand); err != nil {
+		return "", err
+	}
+
+	fence, ok := embedFences[block.format]
+	if !ok && block.format != "markdown" && block.output == "" {
+		return "", usageError("the %s format cannot be embedded without an output file (-o) to link", block.format)
+	}
+
+	if block.output != "" {
+		// the diff of a stale output file is printed along with the ones of the markdown files
+		block.stdout = c.stdout
+	}
+
+	if err := runner(&block, inputs); err != nil {
+		return "", err
+	}
+
+	if block.stale {
+		c.stale = true
+	}
+
+	switch {
+	case block.output != "":
+		link = filepath.ToSlash(link)
+		return fmt.Sprintf("![%s](%s)\n", strings.TrimSuffix(path.Base(link), path.Ext(link)), link), nil
+	case ok:
+		return fmt.Sprintf("```%s\n%s```\n", fence, b.String()), nil
+	}
+
+	return b.String(), nil
+}
+
+// embedError locates the error of a block within the markdown file; an invalid marker is a problem
+// of the markdown file rather than of the command line, so usage errors are reported as parse errors
+func embedError(location string, err error) error {
+	var e *cliError
+	if !errors.As(err, &e) {
+		return fmt.Errorf("%s: %w", location, err)
+	}
+
+	code := e.code
+	if code == exitUsage {
+		code = exitParse
+	}
+
+	return &cliError{code: code, err: fmt.Errorf("%s: %w", location, e.err)}
+}
+
+// resolvePath resolves the relative path against the given directory
+func resolvePath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// splitArgs splits the marker args on spaces; single quotes group the words of an argument
+func splitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		inArg   bool
+	)
+
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			quoted, inArg = !quoted, true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in marker args '%s'", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
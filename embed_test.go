@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunEmbed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services:\n  web:\n    depends_on: [db]\n  db: {}\n"), 0o644))
+
+	readme := filepath.Join(dir, "docs", "README.md")
+	require.NoError(t, os.WriteFile(readme, []byte(
+		"# App\n"+
+			"<!-- compose-graph:start args=\"-format tree ../compose.yaml\" -->\n"+
+			"outdated\n"+
+			"<!-- compose-graph:end -->\n"+
+			"<!-- compose-graph:start args=\"-o graph.svg ../compose.yaml\" -->\n"+
+			"<!-- compose-graph:end -->\n",
+	), 0o644))
+
+	// the blocks are stale
+	code, stdout, stderr := runCLI(t, "embed", "-check", readme)
+	assert.Equal(t, exitFailure, code, stderr)
+	assert.Contains(t, stdout, "-outdated\n")
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "graph.svg"))
+
+	// the paths are relative to the markdown file
+	code, stdout, stderr = runCLI(t, "embed", readme)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+	assert.FileExists(t, filepath.Join(dir, "docs", "graph.svg"))
+
+	b, err := os.ReadFile(readme)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"# App\n"+
+			"<!-- compose-graph:start args=\"-format tree ../compose.yaml\" -->\n"+
+			"```text\n"+
+			"compose.yaml\n"+
+			"└── web\n"+
+			"    └── db (started)\n"+
+			"```\n"+
+			"<!-- compose-graph:end -->\n"+
+			"<!-- compose-graph:start args=\"-o graph.svg ../compose.yaml\" -->\n"+
+			"![graph](graph.svg)\n"+
+			"<!-- compose-graph:end -->\n",
+		string(b),
+	)
+
+	code, stdout, stderr = runCLI(t, "embed", "-check", readme)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	// invalid markers are reported with their location
+	for _, tc := range []struct {
+		contents string
+		expected string
+	}{
+		{"<!-- compose-graph:start args=\"../compose.yaml\" -->\n", "README.md:1: start marker without an end marker"},
+		{"<!-- compose-graph:end -->\n", "README.md:1: end marker without a start marker"},
+		{"\n<!-- compose-graph:start args=\"-format svg ../compose.yaml\" -->\n<!-- compose-graph:end -->\n", "README.md:2: the svg format cannot be embedded without an output file"},
+		{"<!-- compose-graph:start args=\"-unknown ../compose.yaml\" -->\n<!-- compose-graph:end -->\n", "README.md:1: invalid marker args"},
+		{"<!-- compose-graph:start args=\"../missing.yaml\" -->\n<!-- compose-graph:end -->\n", "README.md:1: could not parse"},
+	} {
+		require.NoError(t, os.WriteFile(readme, []byte(tc.contents), 0o644))
+
+		code, _, stderr = runCLI(t, "embed", readme)
+		assert.Equal(t, exitParse, code)
+		assert.Contains(t, stderr, tc.expected)
+	}
+
+	// the markers within code blocks are left as is
+	fenced := "" +
+		"````markdown\n" +
+		"<!-- compose-graph:start args=\"../missing.yaml\" -->\n" +
+		"```mermaid\n" +
+		"```\n" +
+		"<!-- compose-graph:end -->\n" +
+		"````\n" +
+		"~~~\n" +
+		"<!-- compose-graph:end -->\n" +
+		"~~~\n" +
+		"<!-- compose-graph:start args=\"-format tree ../compose.yaml\" -->\n" +
+		"<!-- compose-graph:end -->\n"
+	require.NoError(t, os.WriteFile(readme, []byte(fenced), 0o644))
+
+	code, _, stderr = runCLI(t, "embed", readme)
+	assert.Equal(t, exitOK, code, stderr)
+
+	b, err = os.ReadFile(readme)
+	require.NoError(t, err)
+	assert.Equal(t,
+		strings.TrimSuffix(fenced, "<!-- compose-graph:end -->\n")+
+			"```text\n"+
+			"compose.yaml\n"+
+			"└── web\n"+
+			"    └── db (started)\n"+
+			"```\n"+
+			"<!-- compose-graph:end -->\n",
+		string(b),
+	)
+
+	code, _, stderr = runCLI(t, "embed", "-o", "out.md", readme)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "has no output file")
+}
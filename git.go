@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing/fstest"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+// loadRevision parses the given compose files (or the ones found in the given directories) as of
+// the given git revision into a graph; the paths are relative to the working directory
+func (c *cli) loadRevision(revision string, paths []string) (composegraph.Graph, error) {
+	for _, p := range paths {
+		if p == "-" || !filepath.IsLocal(p) {
+			return composegraph.Graph{}, usageError("'%s' cannot be read from a git revision (expected a path within the working directory)", p)
+		}
+	}
+
+	fsys, err := revisionFS(revision, paths)
+	if err != nil {
+		return composegraph.Graph{}, parseError(err)
+	}
+
+	var project composegraph.Project
+
+	for _, p := range paths {
+		name := filepath.ToSlash(filepath.Clean(p))
+
+		if _, ok := fsys[name]; ok {
+			err = project.AddFS(fsys, name)
+		} else {
+			err = project.AddDirFS(fsys, name, c.dirOptions())
+		}
+		if err != nil {
+			return composegraph.Graph{}, parseError(fmt.Errorf("%s: %w", revision, err))
+		}
+	}
+
+	return c.graph(project)
+}
+
+// revisionFS reads the files under the given paths as of the given git revision into memory; only
+// the yaml & .gitignore files are read from directories, the others are not needed for discovery
+func revisionFS(revision string, paths []string) (fstest.MapFS, error) {
+	out, err := git(append([]string{"ls-tree", "-r", "-z", "--name-only", revision, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := make(fstest.MapFS)
+
+	for _, name := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+
+		explicit := slices.ContainsFunc(paths, func(p string) bool { return filepath.Clean(p) == filepath.Clean(name) })
+		ext := path.Ext(name)
+
+		if !explicit && ext != ".yaml" && ext != ".yml" && path.Base(name) != ".gitignore" {
+			continue
+		}
+
+		data, err := git("show", revision+":./"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		fsys[filepath.ToSlash(name)] = &fstest.MapFile{Data: data}
+	}
+
+	for _, p := range paths {
+		if _, err := fsys.Stat(filepath.ToSlash(filepath.Clean(p))); err != nil {
+			return nil, fmt.Errorf("could not find '%s' in revision '%s'", p, revision)
+		}
+	}
+
+	return fsys, nil
+}
+
+// git runs the git command in the working directory & returns its output
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("could not run git: %w", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepo creates a repository in a temporary working directory with a commit per given version
+// of 'app/compose.yaml'
+func gitRepo(t *testing.T, versions ...string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Chdir(t.TempDir())
+
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	run("init", "-q")
+	require.NoError(t, os.Mkdir("app", 0o755))
+
+	for _, version := range versions {
+		require.NoError(t, os.WriteFile(filepath.Join("app", "compose.yaml"), []byte(version), 0o644))
+		run("add", "-A")
+		run("commit", "-q", "-m", "update")
+	}
+}
+
+func TestRunDiffGit(t *testing.T) {
+	gitRepo(t,
+		"services:\n  web:\n    depends_on: [db]\n  db: {}\n",
+		"services:\n  web:\n    depends_on:\n      db:\n        condition: service_healthy\n  db: {}\n",
+	)
+
+	// the working tree against a revision
+	require.NoError(t, os.WriteFile(filepath.Join("app", "compose.yaml"), []byte("services:\n  web: {}\n  db: {}\n  cache: {}\n"), 0o644))
+
+	code, stdout, stderr := runCLI(t, "diff", "-git", "HEAD", "app")
+	assert.Equal(t, exitFailure, code, stderr)
+	assert.Equal(t, "- dependency web -> db (service_healthy)\n+ service cache (service1)\n", stdout)
+
+	// two revisions, of a directory or a file
+	code, stdout, stderr = runCLI(t, "diff", "-git", "HEAD~1..HEAD", "app")
+	assert.Equal(t, exitFailure, code, stderr)
+	assert.Equal(t, "~ dependency web -> db (service_started → service_healthy)\n", stdout)
+
+	code, stdout, stderr = runCLI(t, "diff", "-git", "HEAD..HEAD", "app/compose.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	code, _, stderr = runCLI(t, "diff", "-git", "HEAD", "missing")
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "could not find 'missing' in revision 'HEAD'")
+
+	code, _, stderr = runCLI(t, "diff", "-git", "HEAD", "../app")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "cannot be read from a git revision")
+}
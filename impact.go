@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+var impactCommand = command{
+	name:    "impact",
+	args:    "<service> <compose-file|dir>...",
+	summary: "rank the services affected if the given service fails, by how they are coupled to it (dot format: highlight them)",
+	formats: append(slices.Clone(textFormats), "json", "dot"),
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		layout := layoutFlags(fs)
+
+		return func(c *cli, args []string) error {
+			switch len(args) {
+			case 0:
+				return usageError("no service given")
+			case 1:
+				return usageError("no compose files given")
+			}
+
+			options := composegraph.RenderOptions{Layout: layout(c.config.Layout)}
+
+			if err := options.Layout.Validate(); err != nil {
+				return usageError("%v", err)
+			}
+
+			g, err := c.load(args[1:])
+			if err != nil {
+				return err
+			}
+
+			failed := findServices(g, args[0])
+			if len(failed) == 0 {
+				return usageError("unknown service '%s'", args[0])
+			}
+
+			impacts := composegraph.ImpactOf(g, failed...)
+
+			if len(impacts) == 0 && c.format == "text" {
+				fmt.Fprintf(c.stderr, "no service is affected by the failure of '%s'\n", args[0])
+			}
+
+			return c.write(func(w io.Writer) error {
+				switch c.format {
+				case "json":
+					return printImpactJSON(w, g, failed, impacts)
+				case "dot":
+					options.Failed = failed
+					return composegraph.Render(w, g, c.format, options)
+				}
+				return printImpact(w, g, impacts)
+			})
+		}
+	},
+}
+
+// findServices returns the services with the given name (in any group) or display name
+func findServices(g composegraph.Graph, name string) []composegraph.NodeID {
+	var ids []composegraph.NodeID
+
+	for gi, group := range g.Groups {
+		for _, node := range group.Nodes {
+			id := composegraph.NodeID{Group: gi, Name: node.Name}
+
+			if node.Category != composegraph.CategoryVolume && (node.Name == name || displayName(g, id) == name) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// via describes what an impacted service is coupled to
+func via(g composegraph.Graph, impact composegraph.Impact) string {
+	if impact.Coupling == composegraph.CouplingSharedVolume {
+		return fmt.Sprintf("%s (volume %s)", displayName(g, impact.Via), displayName(g, impact.Volume))
+	}
+	return displayName(g, impact.Via)
+}
+
+func printImpact(w io.Writer, g composegraph.Graph, impacts []composegraph.Impact) error {
+	if len(impacts) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "rank\tservice\teffective\tcoupling\tvia\tdistance\n")
+
+	for i, impact := range impacts {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\n", i+1, displayName(g, impact.Node), impact.Effective, impact.Coupling, via(g, impact), impact.Distance)
+	}
+
+	return tw.Flush()
+}
+
+type jsonImpacts struct {
+	Failed  []string     `json:"failed"`
+	Impacts []jsonImpact `json:"impacts"`
+}
+
+type jsonImpact struct {
+	Rank      int    `json:"rank"`
+	Name      string `json:"name"`
+	Group     string `json:"group"`
+	Coupling  string `json:"coupling"`
+	Effective string `json:"effective"`
+	Via       string `json:"via"`
+	Volume    string `json:"volume,omitempty"`
+	Distance  int    `json:"distance"`
+}
+
+func printImpactJSON(w io.Writer, g composegraph.Graph, failed []composegraph.NodeID, impacts []composegraph.Impact) error {
+	out := jsonImpacts{
+		Failed:  displayNames(g, failed),
+		Impacts: make([]jsonImpact, 0, len(impacts)),
+	}
+
+	for i, impact := range impacts {
+		j := jsonImpact{
+			Rank:      i + 1,
+			Name:      impact.Node.Name,
+			Group:     g.Groups[impact.Node.Group].Label,
+			Coupling:  impact.Coupling.String(),
+			Effective: impact.Effective.String(),
+			Via:       displayName(g, impact.Via),
+			Distance:  impact.Distance,
+		}
+
+		if impact.Coupling == composegraph.CouplingSharedVolume {
+			j.Volume = displayName(g, impact.Volume)
+		}
+
+		out.Impacts = append(out.Impacts, j)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(out)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const impactCompose = `services:
+  redis:
+    image: redis:7
+    volumes:
+      - data:/data
+  api:
+    depends_on:
+      redis:
+        condition: service_healthy
+  worker:
+    depends_on:
+      redis:
+        condition: service_started
+        restart: true
+  web:
+    depends_on:
+      api:
+        condition: service_started
+        restart: true
+  backup:
+    volumes:
+      - data:/backup:ro
+volumes:
+  data: {}
+`
+
+func TestRunImpact(t *testing.T) {
+	input := writeFile(t, "compose.yaml", impactCompose)
+
+	code, stdout, stderr := runCLI(t, "impact", "redis", input)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, ""+
+		"rank  service  effective            coupling             via                  distance\n"+
+		"1     worker   restart-propagating  restart-propagating  redis                1\n"+
+		"2     api      health-gated         health-gated         redis                1\n"+
+		"3     web      health-gated         restart-propagating  api                  2\n"+
+		"4     backup   shared-volume        shared-volume        redis (volume data)  1\n", stdout)
+
+	code, stdout, stderr = runCLI(t, "impact", "-format", "json", "redis", input)
+	require.Equal(t, exitOK, code, stderr)
+
+	var impacts jsonImpacts
+	require.NoError(t, json.Unmarshal([]byte(stdout), &impacts))
+	assert.Equal(t, []string{"redis"}, impacts.Failed)
+	require.Len(t, impacts.Impacts, 4)
+	assert.Equal(t, jsonImpact{
+		Rank:      4,
+		Name:      "backup",
+		Group:     "compose.yaml",
+		Coupling:  "shared-volume",
+		Effective: "shared-volume",
+		Via:       "redis",
+		Volume:    "data",
+		Distance:  1,
+	}, impacts.Impacts[3])
+
+	// the dot format highlights the failed & affected services
+	code, stdout, stderr = runCLI(t, "impact", "-format", "dot", "redis", input)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `label = "failed"`)
+	assert.Contains(t, stdout, `label = "restart-propagating"`)
+
+	code, stdout, stderr = runCLI(t, "impact", "web", input)
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "no service is affected by the failure of 'web'")
+}
+
+func TestRunImpactUsage(t *testing.T) {
+	input := writeFile(t, "compose.yaml", impactCompose)
+
+	for _, tc := range []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"impact"}, "no service given"},
+		{[]string{"impact", "redis"}, "no compose files given"},
+		{[]string{"impact", "cache", input}, "unknown service 'cache'"},
+		{[]string{"impact", "data", input}, "unknown service 'data'"},
+		{[]string{"impact", "-format", "svg", "redis", input}, "unsupported format 'svg'"},
+	} {
+		code, _, stderr := runCLI(t, tc.args...)
+		assert.Equal(t, exitUsage, code, tc.args)
+		assert.Contains(t, stderr, tc.message, tc.args)
+	}
+}
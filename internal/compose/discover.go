@@ -0,0 +1,197 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/ignore"
+)
+
+// FileNames are the names of the compose files looked up in a directory, in order of precedence
+var FileNames = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+// Project is a directory containing a compose file along with its override file (if any)
+type Project struct {
+	// Dir is the slash-separated path of the directory relative to the discovery root
+	Dir string
+
+	// Files are the paths of the compose files within the file system, overrides last
+	Files []string
+}
+
+// DiscoverOptions control the lookup of the compose files within a directory
+type DiscoverOptions struct {
+	// Recursive walks the subdirectories, honoring the .gitignore files found along the way
+	Recursive bool
+
+	// Ignore are additional .gitignore-style patterns relative to the root directory
+	Ignore []string
+}
+
+// Discover finds the compose projects in the root directory (and its subdirectories if recursive)
+func Discover(fsys fs.FS, root string, options DiscoverOptions) ([]Project, error) {
+	var projects []Project
+
+	err := walkDirs(fsys, root, options, func(dir string, m *ignore.Matcher) error {
+		files, err := projectFiles(fsys, dir, m)
+		if err != nil {
+			return err
+		}
+
+		if len(files) != 0 {
+			rel := dir
+			if root != "." {
+				rel = strings.TrimPrefix(dir, root+"/")
+			}
+			if dir == root {
+				rel = "."
+			}
+			projects = append(projects, Project{Dir: rel, Files: files})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not discover compose files: %w", err)
+	}
+
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no compose files found in '%s' (looked for %s)", root, strings.Join(FileNames, ", "))
+	}
+
+	return projects, nil
+}
+
+// Watched returns the files whose changes may change the discovery in the root directory: the
+// compose files & their override files which are not ignored, along with the .gitignore files
+// honored on the way; the ignored directories are not walked
+func Watched(fsys fs.FS, root string, options DiscoverOptions) ([]string, error) {
+	var watched []string
+
+	err := walkDirs(fsys, root, options, func(dir string, m *ignore.Matcher) error {
+		var names []string
+		for _, name := range FileNames {
+			ext := path.Ext(name)
+			names = append(names, name, strings.TrimSuffix(name, ext)+".override"+ext)
+		}
+		if options.Recursive {
+			names = append(names, ".gitignore")
+		}
+
+		for _, name := range names {
+			file := path.Join(dir, name)
+
+			ok, err := isFile(fsys, file)
+			if err != nil {
+				return err
+			}
+			if ok && !m.Match(file, false) {
+				watched = append(watched, file)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not discover compose files: %w", err)
+	}
+
+	return watched, nil
+}
+
+// walkDirs visits the root directory (and its subdirectories if recursive, skipping the ignored
+// ones) with the matcher of the ignore patterns applying to it
+func walkDirs(fsys fs.FS, root string, options DiscoverOptions, visit func(dir string, m *ignore.Matcher) error) error {
+	var m ignore.Matcher
+
+	if err := m.Add(root, options.Ignore...); err != nil {
+		return err
+	}
+
+	return fs.WalkDir(fsys, root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if dir != root {
+			if !options.Recursive || d.Name() == ".git" || m.Match(dir, true) {
+				return fs.SkipDir
+			}
+		}
+
+		if options.Recursive {
+			if err := addGitignore(&m, fsys, dir); err != nil {
+				return err
+			}
+		}
+
+		return visit(dir, &m)
+	})
+}
+
+// projectFiles returns the first compose file found in the directory along with its override file
+func projectFiles(fsys fs.FS, dir string, m *ignore.Matcher) ([]string, error) {
+	for _, name := range FileNames {
+		base := path.Join(dir, name)
+
+		ok, err := isFile(fsys, base)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || m.Match(base, false) {
+			continue
+		}
+
+		files := []string{base}
+
+		ext := path.Ext(name)
+		override := path.Join(dir, strings.TrimSuffix(name, ext)+".override"+ext)
+
+		ok, err = isFile(fsys, override)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !m.Match(override, false) {
+			files = append(files, override)
+		}
+
+		return files, nil
+	}
+
+	return nil, nil
+}
+
+func addGitignore(m *ignore.Matcher, fsys fs.FS, dir string) error {
+	f, err := fsys.Open(path.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return m.AddFile(dir, f)
+}
+
+func isFile(fsys fs.FS, name string) (bool, error) {
+	info, err := fs.Stat(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
@@ -0,0 +1,92 @@
+package compose
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	fsys := fstest.MapFS{
+		"repo/compose.yaml":                                {},
+		"repo/docker-compose.yml":                          {}, // lower precedence
+		"repo/.gitignore":                                  {Data: []byte("/vendor\n*.generated.yaml\n")},
+		"repo/services/api/docker-compose.yml":             {},
+		"repo/services/api/docker-compose.override.yml":    {},
+		"repo/services/web/compose.yaml":                   {},
+		"repo/services/web/nested/compose.yaml":            {},
+		"repo/services/legacy/docker-compose.yaml":         {},
+		"repo/services/empty/README.md":                    {},
+		"repo/vendor/lib/compose.yaml":                     {},
+		"repo/.git/compose.yaml":                           {},
+		"repo/tools/.gitignore":                            {Data: []byte("*\n")},
+		"repo/tools/compose.yaml":                          {},
+		"repo/other/docker-compose.generated.yaml/foo.txt": {},
+	}
+
+	projects, err := Discover(fsys, "repo", DiscoverOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []Project{{Dir: ".", Files: []string{"repo/compose.yaml"}}}, projects)
+
+	projects, err = Discover(fsys, "repo", DiscoverOptions{Recursive: true, Ignore: []string{"legacy/"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Project{
+		{Dir: ".", Files: []string{"repo/compose.yaml"}},
+		{Dir: "services/api", Files: []string{"repo/services/api/docker-compose.yml", "repo/services/api/docker-compose.override.yml"}},
+		{Dir: "services/web", Files: []string{"repo/services/web/compose.yaml"}},
+		{Dir: "services/web/nested", Files: []string{"repo/services/web/nested/compose.yaml"}},
+	}, projects)
+
+	projects, err = Discover(fsys, "repo/services/api", DiscoverOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ".", projects[0].Dir)
+
+	_, err = Discover(fsys, "repo/services/empty", DiscoverOptions{Recursive: true})
+	require.ErrorContains(t, err, "no compose files found")
+}
+
+func TestDiscoverRoot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"compose.yaml":     {},
+		"a/compose.yaml":   {},
+		"a/b/compose.yaml": {},
+	}
+
+	projects, err := Discover(fsys, ".", DiscoverOptions{Recursive: true})
+	require.NoError(t, err)
+	assert.Equal(t, []Project{
+		{Dir: ".", Files: []string{"compose.yaml"}},
+		{Dir: "a", Files: []string{"a/compose.yaml"}},
+		{Dir: "a/b", Files: []string{"a/b/compose.yaml"}},
+	}, projects)
+}
+
+func TestWatched(t *testing.T) {
+	fsys := fstest.MapFS{
+		"repo/compose.yaml":                    {},
+		"repo/compose.override.yaml":           {},
+		"repo/.gitignore":                      {Data: []byte("/vendor\n")},
+		"repo/README.md":                       {},
+		"repo/services/api/docker-compose.yml": {},
+		"repo/services/api/config.yaml":        {},
+		"repo/vendor/lib/compose.yaml":         {},
+		"repo/node_modules/lib/compose.yaml":   {},
+		"repo/.git/compose.yaml":               {},
+	}
+
+	watched, err := Watched(fsys, "repo", DiscoverOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"repo/compose.yaml", "repo/compose.override.yaml"}, watched)
+
+	// the ignored directories are skipped
+	watched, err = Watched(fsys, "repo", DiscoverOptions{Recursive: true, Ignore: []string{"node_modules/"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"repo/compose.yaml",
+		"repo/compose.override.yaml",
+		"repo/.gitignore",
+		"repo/services/api/docker-compose.yml",
+	}, watched)
+}
@@ -5,19 +5,54 @@ import (
 )
 
 type File struct {
+	// Name is the project name (e.g. as output by 'docker compose config'), empty if not set
+	Name     string
 	Services map[string]Service
 	Volumes  []string
+
+	// Path locates the file (set by ParseFile & ParseFS, see WithPath), e.g. for the volumes it declares
+	Path string
 }
 
 type Service struct {
 	VolumeMounts        []VolumeMount
 	ServiceDependencies []ServiceDependency
 	Labels              map[string]string
+
+	// Image & Ports (in the short '[host_ip:]published:target[/protocol]' syntax) of the service
+	Image string
+	Ports []string
+
+	// Path (set by ParseFile & ParseFS, see WithPath) & Line locate the definition of the service
+	Path string
+	Line int
+}
+
+// WithPath returns the file with its path & the path of the definitions of its services set to the given path
+func (f File) WithPath(path string) File {
+	f.Path = path
+
+	services := make(map[string]Service, len(f.Services))
+
+	for name, service := range f.Services {
+		service.Path = path
+		services[name] = service
+	}
+
+	f.Services = services
+
+	return f
 }
 
 type ServiceDependency struct {
 	On        string
 	Condition Condition
+
+	// Required is false for optional dependencies, which compose skips if the service is missing
+	Required bool
+
+	// Restart propagates the restarts of the dependency (e.g. by 'docker compose restart') to the service
+	Restart bool
 }
 
 type VolumeMount struct {
@@ -40,6 +75,20 @@ const (
 	ConditionServiceCompletedSuccessfully
 )
 
+var conditionStrings = []string{
+	"unknown",
+	"service_started",
+	"service_healthy",
+	"service_completed_successfully",
+}
+
+func (c Condition) String() string {
+	if int(c) < len(conditionStrings) {
+		return conditionStrings[c]
+	}
+	return conditionStrings[ConditionUnknown]
+}
+
 func parseCondition(s string) (Condition, error) {
 	switch s {
 	case "", "service_started":
@@ -64,6 +113,20 @@ const (
 	VolumeTypeTmpfs
 )
 
+var volumeMountTypeStrings = []string{
+	"unknown",
+	"bind",
+	"volume",
+	"tmpfs",
+}
+
+func (t VolumeMountType) String() string {
+	if int(t) < len(volumeMountTypeStrings) {
+		return volumeMountTypeStrings[t]
+	}
+	return volumeMountTypeStrings[VolumeTypeUnknown]
+}
+
 func parseVolumeMountType(s string) (VolumeMountType, error) {
 	switch s {
 	case "", "volume":
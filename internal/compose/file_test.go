@@ -81,3 +81,24 @@ func TestParseVolumeType(t *testing.T) {
 		}
 	}
 }
+
+func TestConditionString(t *testing.T) {
+	for _, c := range []Condition{ConditionServiceStarted, ConditionServiceHealthy, ConditionServiceCompletedSuccessfully} {
+		parsed, err := parseCondition(c.String())
+		assert.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+
+	assert.Equal(t, "unknown", ConditionUnknown.String())
+	assert.Equal(t, "unknown", Condition(42).String())
+}
+
+func TestVolumeMountTypeString(t *testing.T) {
+	for _, v := range []VolumeMountType{VolumeTypeBind, VolumeTypeVolume, VolumeTypeTmpfs} {
+		parsed, err := parseVolumeMountType(v.String())
+		assert.NoError(t, err)
+		assert.Equal(t, v, parsed)
+	}
+
+	assert.Equal(t, "unknown", VolumeTypeUnknown.String())
+}
@@ -0,0 +1,89 @@
+package compose
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// Merge combines the files the way compose applies override files: services & volumes are merged
+// by name; dependencies are merged by service & mounts by target, the later files taking precedence;
+// the services are located at their first definition
+func Merge(files ...File) File {
+	merged := File{Services: make(map[string]Service)}
+
+	for _, f := range files {
+		if f.Name != "" {
+			merged.Name = f.Name
+		}
+
+		// the volumes are located in the first file
+		merged.Path = cmp.Or(merged.Path, f.Path)
+
+		for name, service := range f.Services {
+			existing, ok := merged.Services[name]
+			if !ok {
+				existing = Service{Labels: make(map[string]string)}
+			}
+			merged.Services[name] = mergeService(existing, service)
+		}
+
+		for _, volume := range f.Volumes {
+			if !slices.Contains(merged.Volumes, volume) {
+				merged.Volumes = append(merged.Volumes, volume)
+			}
+		}
+	}
+
+	slices.Sort(merged.Volumes)
+
+	return merged
+}
+
+func mergeService(base, override Service) Service {
+	merged := Service{
+		VolumeMounts:        slices.Clone(base.VolumeMounts),
+		ServiceDependencies: slices.Clone(base.ServiceDependencies),
+		Labels:              maps.Clone(base.Labels),
+		Image:               cmp.Or(override.Image, base.Image),
+		Ports:               slices.Clone(base.Ports),
+		Path:                cmp.Or(base.Path, override.Path),
+		Line:                cmp.Or(base.Line, override.Line),
+	}
+
+	for _, port := range override.Ports {
+		if !slices.Contains(merged.Ports, port) {
+			merged.Ports = append(merged.Ports, port)
+		}
+	}
+
+	for _, dependency := range override.ServiceDependencies {
+		i := slices.IndexFunc(merged.ServiceDependencies, func(d ServiceDependency) bool { return d.On == dependency.On })
+		if i == -1 {
+			merged.ServiceDependencies = append(merged.ServiceDependencies, dependency)
+		} else {
+			merged.ServiceDependencies[i] = dependency
+		}
+	}
+
+	// the dependencies are kept sorted as when parsed, regardless of the file defining them
+	slices.SortFunc(merged.ServiceDependencies, func(a, b ServiceDependency) int {
+		return cmp.Compare(a.On, b.On)
+	})
+
+	for _, mount := range override.VolumeMounts {
+		i := slices.IndexFunc(merged.VolumeMounts, func(m VolumeMount) bool { return m.Target == mount.Target })
+		if i == -1 {
+			merged.VolumeMounts = append(merged.VolumeMounts, mount)
+		} else {
+			merged.VolumeMounts[i] = mount
+		}
+	}
+
+	if merged.Labels == nil {
+		merged.Labels = make(map[string]string)
+	}
+	maps.Copy(merged.Labels, override.Labels)
+
+	return merged
+}
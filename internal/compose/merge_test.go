@@ -0,0 +1,79 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	base := File{
+		Services: map[string]Service{
+			"app": {
+				ServiceDependencies: []ServiceDependency{
+					{On: "db", Condition: ConditionServiceStarted},
+				},
+				VolumeMounts: []VolumeMount{
+					{Type: VolumeTypeVolume, Source: "data", Target: "/data"},
+				},
+				Labels: map[string]string{"graph.node.category": "service1"},
+				Image:  "app:1",
+				Ports:  []string{"8080:80"},
+				Path:   "compose.yaml",
+				Line:   3,
+			},
+			"db": {Labels: map[string]string{}},
+		},
+		Volumes: []string{"data"},
+		Path:    "compose.yaml",
+	}
+
+	override := File{
+		Services: map[string]Service{
+			"app": {
+				ServiceDependencies: []ServiceDependency{
+					{On: "cache", Condition: ConditionServiceStarted},
+					{On: "db", Condition: ConditionServiceHealthy},
+				},
+				VolumeMounts: []VolumeMount{
+					{Type: VolumeTypeVolume, Source: "data", Target: "/data", ReadOnly: true},
+				},
+				Labels: map[string]string{"graph.node.label": "App"},
+				Image:  "app:2",
+				Ports:  []string{"8080:80", "9090:90"},
+				Path:   "compose.override.yaml",
+				Line:   2,
+			},
+			"cache": {Labels: map[string]string{}, Path: "compose.override.yaml", Line: 8},
+		},
+		Volumes: []string{"cache-data", "data"},
+		Path:    "compose.override.yaml",
+	}
+
+	assert.Equal(t, File{
+		Services: map[string]Service{
+			"app": {
+				ServiceDependencies: []ServiceDependency{
+					{On: "cache", Condition: ConditionServiceStarted},
+					{On: "db", Condition: ConditionServiceHealthy},
+				},
+				VolumeMounts: []VolumeMount{
+					{Type: VolumeTypeVolume, Source: "data", Target: "/data", ReadOnly: true},
+				},
+				Labels: map[string]string{"graph.node.category": "service1", "graph.node.label": "App"},
+				Image:  "app:2",
+				Ports:  []string{"8080:80", "9090:90"},
+				Path:   "compose.yaml",
+				Line:   3,
+			},
+			"cache": {Labels: map[string]string{}, Path: "compose.override.yaml", Line: 8},
+			"db":    {Labels: map[string]string{}},
+		},
+		Volumes: []string{"cache-data", "data"},
+		Path:    "compose.yaml",
+	}, Merge(base, override))
+
+	// the inputs are left untouched
+	assert.Len(t, base.Services["app"].ServiceDependencies, 1)
+	assert.Equal(t, ConditionServiceStarted, base.Services["app"].ServiceDependencies[0].Condition)
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"os"
 	"slices"
 	"strings"
 
 	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
 )
 
 func ParseFile(path string) (_ File, errs error) {
@@ -24,7 +27,32 @@ func ParseFile(path string) (_ File, errs error) {
 		}
 	}()
 
-	return Parse(f)
+	parsed, err := Parse(f)
+	if err != nil {
+		return File{}, err
+	}
+
+	return parsed.WithPath(path), nil
+}
+
+// ParseFS parses the file at the given path within the file system (e.g. a git tree or an archive)
+func ParseFS(fsys fs.FS, path string) (_ File, errs error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("could not open: %w", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("could not close: %w", err))
+		}
+	}()
+
+	parsed, err := Parse(f)
+	if err != nil {
+		return File{}, err
+	}
+
+	return parsed.WithPath(path), nil
 }
 
 func Parse(r io.Reader) (File, error) {
@@ -39,9 +67,54 @@ func Parse(r io.Reader) (File, error) {
 		return File{}, fmt.Errorf("could not unmarshal yaml contents: %w", err)
 	}
 
+	for name, line := range serviceLines(b) {
+		if service, ok := parsed.Services[name]; ok {
+			service.Line = line
+			parsed.Services[name] = service
+		}
+	}
+
 	return parsed, nil
 }
 
+// serviceLines locates the keys of the services in the yaml contents; the lines are best effort
+// (e.g. none are returned for services declared through a merge key)
+func serviceLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return lines
+	}
+
+	p, err := yaml.PathString("$.services")
+	if err != nil {
+		return lines
+	}
+
+	node, err := p.FilterFile(file)
+	if err != nil {
+		return lines
+	}
+
+	var values []*ast.MappingValueNode
+
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		values = n.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{n}
+	}
+
+	for _, v := range values {
+		if token := v.Key.GetToken(); token != nil {
+			lines[token.Value] = token.Position.Line
+		}
+	}
+
+	return lines
+}
+
 //
 // helpers for parsing
 //
@@ -75,6 +148,48 @@ func (r *rawVolumeMount) UnmarshalYAML(unmarshal func(any) error) error {
 	return fmt.Errorf("invalid volume format")
 }
 
+// rawPort supports both short & long 'ports' formats, normalizing them into the short format
+type rawPort string
+
+type rawPortLong struct {
+	Target    any    `yaml:"target,omitempty"`
+	Published any    `yaml:"published,omitempty"`
+	HostIP    string `yaml:"host_ip,omitempty"`
+	Protocol  string `yaml:"protocol,omitempty"`
+}
+
+func (r *rawPort) UnmarshalYAML(unmarshal func(any) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		*r = rawPort(short)
+		return nil
+	}
+
+	var long rawPortLong
+	if err := unmarshal(&long); err != nil || long.Target == nil {
+		return fmt.Errorf("invalid port format")
+	}
+
+	port := fmt.Sprint(long.Target)
+
+	if long.Published != nil && fmt.Sprint(long.Published) != "" {
+		port = fmt.Sprint(long.Published) + ":" + port
+	}
+
+	if long.HostIP != "" {
+		port = long.HostIP + ":" + port
+	}
+
+	// tcp is the default protocol
+	if long.Protocol != "" && long.Protocol != "tcp" {
+		port += "/" + long.Protocol
+	}
+
+	*r = rawPort(port)
+
+	return nil
+}
+
 // rawDependsOn supports both list and map formats
 type rawDependsOn struct {
 	List []string
@@ -83,6 +198,8 @@ type rawDependsOn struct {
 
 type rawDependsOnCondition struct {
 	Condition string `yaml:"condition,omitempty"`
+	Required  *bool  `yaml:"required,omitempty"`
+	Restart   bool   `yaml:"restart,omitempty"`
 }
 
 func (r *rawDependsOn) UnmarshalYAML(unmarshal func(any) error) error {
@@ -128,6 +245,8 @@ func (s *Service) UnmarshalYAML(unmarshal func(any) error) error {
 		VolumeMounts []rawVolumeMount `yaml:"volumes,omitempty"`
 		DependsOn    rawDependsOn     `yaml:"depends_on,omitempty"`
 		Labels       rawLabels        `yaml:"labels,omitempty"`
+		Image        string           `yaml:"image,omitempty"`
+		Ports        []rawPort        `yaml:"ports,omitempty"`
 	}
 	if err := unmarshal(&raw); err != nil {
 		return err
@@ -138,6 +257,7 @@ func (s *Service) UnmarshalYAML(unmarshal func(any) error) error {
 		s.ServiceDependencies = append(s.ServiceDependencies, ServiceDependency{
 			On:        dependency,
 			Condition: ConditionServiceStarted, // default
+			Required:  true,                    // default
 		})
 	}
 
@@ -149,6 +269,8 @@ func (s *Service) UnmarshalYAML(unmarshal func(any) error) error {
 		s.ServiceDependencies = append(s.ServiceDependencies, ServiceDependency{
 			On:        name,
 			Condition: c,
+			Required:  condition.Required == nil || *condition.Required, // required by default
+			Restart:   condition.Restart,
 		})
 	}
 
@@ -192,6 +314,12 @@ func (s *Service) UnmarshalYAML(unmarshal func(any) error) error {
 		}
 	}
 
+	s.Image = raw.Image
+
+	for _, port := range raw.Ports {
+		s.Ports = append(s.Ports, string(port))
+	}
+
 	// normalize labels
 	s.Labels = make(map[string]string)
 
@@ -212,6 +340,7 @@ func (s *Service) UnmarshalYAML(unmarshal func(any) error) error {
 
 func (s *File) UnmarshalYAML(unmarshal func(any) error) error {
 	var raw struct {
+		Name     string             `yaml:"name,omitempty"`
 		Services map[string]Service `yaml:"services"`
 		Volumes  map[string]any     `yaml:"volumes,omitempty"`
 	}
@@ -227,6 +356,7 @@ func (s *File) UnmarshalYAML(unmarshal func(any) error) error {
 
 	slices.Sort(volumes)
 
+	s.Name = raw.Name
 	s.Services = raw.Services
 	s.Volumes = volumes
 
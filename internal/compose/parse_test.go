@@ -3,6 +3,7 @@ package compose
 import (
 	"bytes"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -82,6 +83,7 @@ volumes:
 		[]ServiceDependency{{
 			On:        "service1",
 			Condition: ConditionServiceStarted,
+			Required:  true,
 		}},
 		service2.ServiceDependencies,
 	)
@@ -105,6 +107,7 @@ volumes:
 		[]ServiceDependency{{
 			On:        "service2",
 			Condition: ConditionServiceHealthy,
+			Required:  true,
 		}},
 		service3.ServiceDependencies,
 	)
@@ -112,3 +115,140 @@ volumes:
 	// volumes
 	assert.Equal(t, []string{"my-volume"}, parsed.Volumes)
 }
+
+// TestParseComposeConfig parses the canonical output of 'docker compose config'
+func TestParseComposeConfig(t *testing.T) {
+	config := `
+name: simple
+services:
+  my-database:
+    image: postgres:17
+    healthcheck:
+      test:
+        - CMD-SHELL
+        - pg_isready
+      interval: 5s
+    networks:
+      default: null
+    ports:
+      - mode: ingress
+        target: 5432
+        published: "5432"
+        protocol: tcp
+    volumes:
+      - type: volume
+        source: my-volume
+        target: /var/lib/postgresql/data
+        volume: {}
+      - type: bind
+        source: /home/user/project/init
+        target: /docker-entrypoint-initdb.d
+        read_only: true
+        bind:
+          create_host_path: true
+      - type: tmpfs
+        target: /run
+        tmpfs:
+          size: "1024"
+  my-service:
+    depends_on:
+      my-database:
+        condition: service_healthy
+        required: true
+        restart: true
+    image: nginx:latest
+    labels:
+      graph.node.category: ui
+    networks:
+      default: null
+networks:
+  default:
+    name: simple_default
+volumes:
+  my-volume:
+    name: simple_my-volume
+`
+
+	parsed, err := Parse(bytes.NewReader([]byte(config)))
+	require.NoError(t, err)
+	assert.Equal(t, "simple", parsed.Name)
+	assert.Equal(t, []string{"my-volume"}, parsed.Volumes)
+	assert.Equal(t, []VolumeMount{
+		{Type: VolumeTypeVolume, Source: "my-volume", Target: "/var/lib/postgresql/data"},
+		{Type: VolumeTypeBind, Source: "/home/user/project/init", Target: "/docker-entrypoint-initdb.d", ReadOnly: true},
+		{Type: VolumeTypeTmpfs, Target: "/run"},
+	}, parsed.Services["my-database"].VolumeMounts)
+	assert.Equal(t, []ServiceDependency{
+		{On: "my-database", Condition: ConditionServiceHealthy, Required: true, Restart: true},
+	}, parsed.Services["my-service"].ServiceDependencies)
+	assert.Equal(t, map[string]string{"graph.node.category": "ui"}, parsed.Services["my-service"].Labels)
+	assert.Equal(t, "postgres:17", parsed.Services["my-database"].Image)
+	assert.Equal(t, []string{"5432:5432"}, parsed.Services["my-database"].Ports)
+	assert.Equal(t, 4, parsed.Services["my-database"].Line)
+	assert.Equal(t, 33, parsed.Services["my-service"].Line)
+}
+
+func TestParsePorts(t *testing.T) {
+	config := `
+services:
+  web:
+    ports:
+      - 80
+      - "8080:80"
+      - target: 53
+        published: "5353"
+        host_ip: 127.0.0.1
+        protocol: udp
+`
+
+	parsed, err := Parse(bytes.NewReader([]byte(config)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"80", "8080:80", "127.0.0.1:5353:53/udp"}, parsed.Services["web"].Ports)
+	assert.Equal(t, 3, parsed.Services["web"].Line)
+}
+
+func TestParseDependsOn(t *testing.T) {
+	parsed, err := Parse(bytes.NewReader([]byte(`
+services:
+  app:
+    depends_on:
+      cache:
+        condition: service_started
+        required: false
+      db:
+        condition: service_healthy
+        restart: true
+      migrations:
+        condition: service_completed_successfully
+        required: true
+        restart: false
+`)))
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDependency{
+		{On: "cache", Condition: ConditionServiceStarted, Required: false},
+		{On: "db", Condition: ConditionServiceHealthy, Required: true, Restart: true},
+		{On: "migrations", Condition: ConditionServiceCompletedSuccessfully, Required: true},
+	}, parsed.Services["app"].ServiceDependencies)
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"project/compose.yaml": {Data: []byte(`
+services:
+  app:
+    depends_on:
+      - db
+  db:
+    image: postgres:16
+`)},
+	}
+
+	parsed, err := ParseFS(fsys, "project/compose.yaml")
+	require.NoError(t, err)
+	require.Len(t, parsed.Services, 2)
+	assert.Equal(t, "project/compose.yaml", parsed.Path)
+	assert.Equal(t, []ServiceDependency{{On: "db", Condition: ConditionServiceStarted, Required: true}}, parsed.Services["app"].ServiceDependencies)
+
+	_, err = ParseFS(fsys, "project/missing.yaml")
+	require.Error(t, err)
+}
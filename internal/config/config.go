@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Config holds the defaults for the command line flags; flags which are set explicitly take precedence
+type Config struct {
+	// Format is the output format of the graph command (e.g. "svg")
+	Format string `yaml:"format"`
+
+	// Theme is the name of the node color theme
+	Theme string `yaml:"theme"`
+
+	// Legend is the placement of the legend of the graph & serve commands ("inline", "none" or
+	// "only"), ignored by the formats without a legend
+	Legend string `yaml:"legend"`
+
+	// Include & Exclude are glob patterns of the service & volume names to keep or remove
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	// Categories overrides the category of the services & volumes by name
+	Categories map[string]string `yaml:"categories"`
+
+	// Ignore are .gitignore-style patterns of the paths to skip when searching directories
+	Ignore []string `yaml:"ignore"`
+
+	// Layout holds the defaults for the layout flags of the graph command (dot format)
+	Layout Layout `yaml:"layout"`
+
+	// Annotations holds the defaults for the tooltip & hyperlink flags of the graph command (dot & svg formats)
+	Annotations Annotations `yaml:"annotations"`
+
+	// Lint holds the settings of the lint command
+	Lint Lint `yaml:"lint"`
+}
+
+// Layout holds the graphviz attributes controlling the arrangement of the graph
+type Layout struct {
+	RankDir     string  `yaml:"rankdir"`
+	NodeSep     float64 `yaml:"nodesep"`
+	RankSep     float64 `yaml:"ranksep"`
+	Splines     string  `yaml:"splines"`
+	Concentrate bool    `yaml:"concentrate"`
+	FontName    string  `yaml:"fontname"`
+	FontSize    float64 `yaml:"fontsize"`
+	VolumesSink bool    `yaml:"volumes-sink"`
+}
+
+// Annotations holds the tooltips & the hyperlink template (e.g. 'https://git.example/{{.File}}#L{{.Line}}')
+// of the nodes & edges
+type Annotations struct {
+	Tooltips bool   `yaml:"tooltips"`
+	URL      string `yaml:"url"`
+	Target   string `yaml:"target"`
+}
+
+// Lint holds the severities of the lint rules by id ("error", "warning", "note" or "off")
+type Lint struct {
+	Rules map[string]string `yaml:"rules"`
+}
+
+// Load reads the yaml configuration file at the given path; unknown fields are rejected
+// to catch typos early
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read config: %w", err)
+	}
+
+	var c Config
+
+	if err := yaml.UnmarshalWithOptions(b, &c, yaml.DisallowUnknownField()); err != nil {
+		return Config{}, errors.New("could not parse config: " + yaml.FormatError(err, false, true))
+	}
+
+	return c, nil
+}
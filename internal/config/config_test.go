@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+format: svg
+theme: monochrome
+exclude:
+  - "*-tool"
+categories:
+  db: database
+ignore:
+  - legacy/
+legend: none
+layout:
+  rankdir: LR
+  ranksep: 1.5
+  splines: ortho
+  volumes-sink: true
+annotations:
+  tooltips: true
+  url: "https://git.example/{{.File}}#L{{.Line}}"
+lint:
+  rules:
+    latest-image-tag: error
+    missing-category-label: off
+`), 0o644))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Format:     "svg",
+		Theme:      "monochrome",
+		Exclude:    []string{"*-tool"},
+		Categories: map[string]string{"db": "database"},
+		Ignore:     []string{"legacy/"},
+		Legend:     "none",
+		Layout: Layout{
+			RankDir:     "LR",
+			RankSep:     1.5,
+			Splines:     "ortho",
+			VolumesSink: true,
+		},
+		Annotations: Annotations{
+			Tooltips: true,
+			URL:      "https://git.example/{{.File}}#L{{.Line}}",
+		},
+		Lint: Lint{
+			Rules: map[string]string{"latest-image-tag": "error", "missing-category-label": "off"},
+		},
+	}, c)
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorContains(t, err, "could not read config")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("fromat: svg\n"), 0o644))
+
+	_, err = Load(path)
+	require.ErrorContains(t, err, "fromat")
+}
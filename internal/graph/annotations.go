@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// graphNodeURL is the label overriding the hyperlink of a service
+const graphNodeURL = "graph.node.url"
+
+// Annotations control the tooltips & hyperlinks attached to the nodes & edges (dot, svg)
+type Annotations struct {
+	// Tooltips summarizes the image, ports & mounts of the nodes & the conditions & targets of the edges
+	Tooltips bool
+
+	// URL is a text/template of the hyperlinks of the services, executed against URLData (e.g.
+	// 'https://git.example/{{.File}}#L{{.Line}}'); the 'graph.node.url' label takes precedence
+	URL string
+
+	// Target is the window in which the hyperlinks are opened (e.g. '_blank')
+	Target string
+}
+
+// URLData is the data available to the URL template
+type URLData struct {
+	// Name & Group are the name of the service & the label of its node group
+	Name  string
+	Group string
+
+	// File & Line locate the definition of the service
+	File string
+	Line int
+}
+
+// Validate checks that the URL template can be parsed & only refers to the fields of URLData
+func (a Annotations) Validate() error {
+	t, err := a.template()
+	if err != nil || t == nil {
+		return err
+	}
+
+	if err := t.Execute(io.Discard, URLData{}); err != nil {
+		return fmt.Errorf("invalid url template: %w", err)
+	}
+
+	return nil
+}
+
+func (a Annotations) template() (*template.Template, error) {
+	if a.URL == "" {
+		return nil, nil
+	}
+
+	t, err := template.New("url").Parse(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url template: %w", err)
+	}
+
+	return t, nil
+}
+
+// annotator computes the tooltips & hyperlinks of the nodes & edges of a graph
+type annotator struct {
+	Annotations
+
+	g   Graph
+	url *template.Template
+}
+
+func newAnnotator(g Graph, annotations Annotations) (annotator, error) {
+	url, err := annotations.template()
+	if err != nil {
+		return annotator{}, err
+	}
+
+	return annotator{Annotations: annotations, g: g, url: url}, nil
+}
+
+// nodeTooltip returns the lines describing the image, the ports & the mounts of a service or the
+// services mounting a volume; empty if the tooltips are disabled or there is nothing to describe
+func (a annotator) nodeTooltip(id NodeID, node Node) []string {
+	if !a.Tooltips {
+		return nil
+	}
+
+	var lines []string
+
+	if node.Image != "" {
+		lines = append(lines, "image: "+node.Image)
+	}
+
+	if len(node.Ports) != 0 {
+		lines = append(lines, "ports: "+strings.Join(node.Ports, ", "))
+	}
+
+	for _, e := range a.g.Outgoing(id) {
+		if e.Kind == EdgeKindVolumeMount {
+			lines = append(lines, fmt.Sprintf("mounts: %s:%s (%s)", e.To.Name, e.Target, readWrite(e.ReadOnly)))
+		}
+	}
+
+	for _, e := range a.g.Incoming(id) {
+		if e.Kind == EdgeKindVolumeMount {
+			lines = append(lines, fmt.Sprintf("mounted by: %s:%s (%s)", e.From.Name, e.Target, readWrite(e.ReadOnly)))
+		}
+	}
+
+	return lines
+}
+
+// nodeURL returns the hyperlink of a service: its 'graph.node.url' label or the executed URL
+// template; volumes are not linked
+func (a annotator) nodeURL(id NodeID, node Node) (string, error) {
+	if node.Category == CategoryVolume {
+		return "", nil
+	}
+
+	if url, ok := node.Labels[graphNodeURL]; ok {
+		return url, nil
+	}
+
+	if a.url == nil {
+		return "", nil
+	}
+
+	var group string
+	if id.Group < len(a.g.Groups) {
+		group = a.g.Groups[id.Group].Label
+	}
+
+	var b strings.Builder
+
+	if err := a.url.Execute(&b, URLData{Name: node.Name, Group: group, File: node.Path, Line: node.Line}); err != nil {
+		return "", fmt.Errorf("could not execute url template for '%s': %w", node.Name, err)
+	}
+
+	return b.String(), nil
+}
+
+// edgeTooltip describes the condition of a dependency or the target of a mount; empty if the
+// tooltips are disabled
+func (a annotator) edgeTooltip(e Edge) string {
+	if !a.Tooltips {
+		return ""
+	}
+
+	if e.Kind == EdgeKindVolumeMount {
+		return fmt.Sprintf("%s → %s: %s (%s)", e.From.Name, e.To.Name, e.Target, readWrite(e.ReadOnly))
+	}
+
+	return fmt.Sprintf("%s → %s: %s", e.From.Name, e.To.Name, dependencyNotes(e.Condition.String(), e))
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func annotatedGraph() Graph {
+	return NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "app", Label: "app", Category: CategoryService1, Image: "app:1", Ports: []string{"8080:80"}, Path: "deploy/compose.yaml", Line: 3},
+			{Name: "db", Label: "db", Category: CategoryDatabase, Labels: map[string]string{graphNodeURL: "https://db.example?a=1&b=2"}},
+			{Name: "data", Label: "data", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "app", "db", compose.ConditionServiceHealthy),
+		mounts(0, "app", "data", "/cache", false),
+		mounts(0, "app", "data", "/config", true),
+	})
+}
+
+func TestAnnotationsValidate(t *testing.T) {
+	assert.NoError(t, Annotations{}.Validate())
+	assert.NoError(t, Annotations{URL: "https://git.example/{{.File}}#L{{.Line}}"}.Validate())
+
+	assert.ErrorContains(t, Annotations{URL: "{{.File"}.Validate(), "invalid url template")
+	assert.ErrorContains(t, Annotations{URL: "{{.Path}}"}.Validate(), "can't evaluate field Path")
+}
+
+func TestAnnotator(t *testing.T) {
+	g := annotatedGraph()
+
+	a, err := newAnnotator(g, Annotations{Tooltips: true, URL: "https://git.example/{{.Group}}/{{.File}}#L{{.Line}}"})
+	require.NoError(t, err)
+
+	app, _ := g.Node(NodeID{Name: "app"})
+	db, _ := g.Node(NodeID{Name: "db"})
+	data, _ := g.Node(NodeID{Name: "data"})
+
+	assert.Equal(t, []string{
+		"image: app:1",
+		"ports: 8080:80",
+		"mounts: data:/cache (rw)",
+		"mounts: data:/config (ro)",
+	}, a.nodeTooltip(NodeID{Name: "app"}, app))
+	assert.Empty(t, a.nodeTooltip(NodeID{Name: "db"}, db))
+	assert.Equal(t, []string{
+		"mounted by: app:/cache (rw)",
+		"mounted by: app:/config (ro)",
+	}, a.nodeTooltip(NodeID{Name: "data"}, data))
+
+	url, err := a.nodeURL(NodeID{Name: "app"}, app)
+	require.NoError(t, err)
+	assert.Equal(t, "https://git.example/docker-compose.yaml/deploy/compose.yaml#L3", url)
+
+	// the label takes precedence & the volumes are not linked
+	url, err = a.nodeURL(NodeID{Name: "db"}, db)
+	require.NoError(t, err)
+	assert.Equal(t, "https://db.example?a=1&b=2", url)
+
+	url, err = a.nodeURL(NodeID{Name: "data"}, data)
+	require.NoError(t, err)
+	assert.Empty(t, url)
+
+	assert.Equal(t, "app → db: service_healthy", a.edgeTooltip(g.Edges[0]))
+	assert.Equal(t, "app → data: /config (ro)", a.edgeTooltip(g.Edges[2]))
+
+	// the tooltips are opt-in
+	a, err = newAnnotator(g, Annotations{})
+	require.NoError(t, err)
+	assert.Empty(t, a.nodeTooltip(NodeID{Name: "app"}, app))
+	assert.Empty(t, a.edgeTooltip(g.Edges[0]))
+}
+
+func TestPrintAnnotations(t *testing.T) {
+	g := annotatedGraph()
+	annotations := Annotations{Tooltips: true, URL: "https://git.example/{{.File}}#L{{.Line}}", Target: "_blank"}
+
+	var b strings.Builder
+	require.NoError(t, PrintDOT(&b, g, DOTOptions{Annotations: annotations}))
+
+	assert.Contains(t, b.String(), `label = "app" tooltip = "image: app:1\nports: 8080:80\nmounts: data:/cache (rw)\nmounts: data:/config (ro)" URL = "https://git.example/deploy/compose.yaml#L3" target = "_blank"];`)
+	assert.Contains(t, b.String(), `label = "db" URL = "https://db.example?a=1&b=2" target = "_blank"];`)
+	assert.Contains(t, b.String(), `tooltip="app → data: /cache (rw)\napp → data: /config (ro)"];`)
+
+	// the legend is not annotated
+	assert.NotContains(t, b.String(), `label = "database" tooltip`)
+
+	b.Reset()
+	require.NoError(t, PrintSVG(&b, g, SVGOptions{Annotations: annotations}))
+
+	assert.Contains(t, b.String(), "<title>image: app:1\nports: 8080:80\n")
+	assert.Contains(t, b.String(), `<a href="https://db.example?a=1&amp;b=2" target="_blank">`)
+	assert.Contains(t, b.String(), `<title>app → db: service_healthy</title></path>`)
+
+	// nothing is annotated by default, apart from the services with a url label
+	b.Reset()
+	require.NoError(t, PrintDOT(&b, g, DOTOptions{}))
+	assert.NotContains(t, b.String(), "tooltip")
+	assert.Equal(t, 1, strings.Count(b.String(), "URL"))
+
+	assert.Error(t, PrintDOT(&b, g, DOTOptions{Annotations: Annotations{URL: "{{.File"}}))
+}
@@ -64,6 +64,15 @@ func (d Category) String() string {
 	return categoryStrings[d]
 }
 
+// ParseCategory returns the category with the given name (e.g. "database")
+func ParseCategory(name string) (Category, bool) {
+	i := slices.Index(categoryStrings, name)
+	if i == -1 {
+		return CategoryNone, false
+	}
+	return Category(i), true
+}
+
 // guessPatterns are evaluated sequentially to guess the service category
 var guessPatterns = []struct {
 	category Category
@@ -25,3 +25,14 @@ func TestConsitency(t *testing.T) {
 	assert.Equal(t, int(categoryCount), len(categoryStrings), "inconsitent number of category strings")
 	assert.Equal(t, int(categoryCount), len(categoryDecorations), "inconsitent number of category decorations")
 }
+
+func TestParseCategory(t *testing.T) {
+	for category := CategoryNone; category < categoryCount; category++ {
+		parsed, ok := ParseCategory(category.String())
+		assert.True(t, ok)
+		assert.Equal(t, category, parsed)
+	}
+
+	_, ok := ParseCategory("not-a-category")
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package graph
+
+import "slices"
+
+// contendedPenWidth is the width of the border of the volumes mounted read-write by several services
+const contendedPenWidth = 3
+
+// VolumeAccess lists the services mounting a volume, from any group; a service mounting the volume
+// both read-write & read-only is only listed as a writer
+type VolumeAccess struct {
+	Volume  NodeID
+	Writers []NodeID
+	Readers []NodeID
+}
+
+// Contended reports whether the volume is mounted read-write by more than one service, which risks
+// corrupting its data
+func (a VolumeAccess) Contended() bool {
+	return len(a.Writers) > 1
+}
+
+// VolumeAccesses returns the writers & readers of each volume of the graph, ordered by group & name
+// (as are the writers & readers)
+func VolumeAccesses(g Graph) []VolumeAccess {
+	var accesses []VolumeAccess
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category == CategoryVolume {
+			accesses = append(accesses, volumeAccess(g, id))
+		}
+	})
+
+	return accesses
+}
+
+func volumeAccess(g Graph, id NodeID) VolumeAccess {
+	a := VolumeAccess{Volume: id}
+
+	for _, e := range g.Incoming(id) {
+		if e.Kind != EdgeKindVolumeMount {
+			continue
+		}
+
+		if e.ReadOnly {
+			a.Readers = append(a.Readers, e.From)
+		} else {
+			a.Writers = append(a.Writers, e.From)
+		}
+	}
+
+	slices.SortFunc(a.Writers, compareNodeIDs)
+	slices.SortFunc(a.Readers, compareNodeIDs)
+
+	a.Writers = slices.Compact(a.Writers)
+	a.Readers = slices.DeleteFunc(slices.Compact(a.Readers), func(id NodeID) bool {
+		return slices.Contains(a.Writers, id)
+	})
+
+	return a
+}
+
+// contendedVolumes returns the volumes mounted read-write by more than one service
+func contendedVolumes(g Graph) map[NodeID]bool {
+	contended := make(map[NodeID]bool)
+
+	for _, a := range VolumeAccesses(g) {
+		if a.Contended() {
+			contended[a.Volume] = true
+		}
+	}
+
+	return contended
+}
+
+// contendedDecorations outlines a contended volume in red
+func contendedDecorations(d Decorations) Decorations {
+	d.palette.ColorBorder = Red
+	return d
+}
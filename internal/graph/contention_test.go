@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func contentionGraph() Graph {
+	return NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "backup", Label: "backup", Category: CategoryTool},
+			{Name: "cache", Label: "cache", Category: CategoryVolume},
+			{Name: "data", Label: "data", Category: CategoryVolume},
+			{Name: "reader", Label: "reader", Category: CategoryService1},
+			{Name: "writer", Label: "writer", Category: CategoryService1},
+		},
+	}}, []Edge{
+		mounts(0, "writer", "data", "/data", false),
+		mounts(0, "backup", "data", "/backup", false),
+		mounts(0, "reader", "data", "/data", true),
+		// a second mount of the same volume makes the writer neither a second writer nor a reader
+		mounts(0, "writer", "data", "/snapshot", true),
+		mounts(0, "writer", "cache", "/cache", false),
+	})
+}
+
+func TestVolumeAccesses(t *testing.T) {
+	id := func(name string) NodeID { return NodeID{Group: 0, Name: name} }
+
+	accesses := VolumeAccesses(contentionGraph())
+
+	assert.Equal(t, []VolumeAccess{
+		{Volume: id("cache"), Writers: []NodeID{id("writer")}},
+		{Volume: id("data"), Writers: []NodeID{id("backup"), id("writer")}, Readers: []NodeID{id("reader")}},
+	}, accesses)
+
+	assert.False(t, accesses[0].Contended())
+	assert.True(t, accesses[1].Contended())
+}
+
+// dotNode returns the line of the dot-graph declaring the given node
+func dotNode(dot, name string) string {
+	for _, line := range strings.Split(dot, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), name+" ") && !strings.Contains(line, "->") {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestPrintContendedVolumes(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, Print(&b, contentionGraph()))
+
+	assert.Contains(t, dotNode(b.String(), "data"), `color = "/orrd8/7"`)
+	assert.Contains(t, dotNode(b.String(), "data"), `penwidth = "3"`)
+	assert.NotContains(t, dotNode(b.String(), "cache"), "penwidth")
+	assert.Contains(t, dotNode(b.String(), "legend_contended_volume"), `label = "shared writable volume" penwidth = "3"`)
+
+	// the legend sample is only drawn when needed
+	b.Reset()
+	require.NoError(t, Print(&b, NewGraph(contentionGraph().Groups, nil)))
+	assert.NotContains(t, b.String(), "legend_contended_volume")
+}
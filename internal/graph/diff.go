@@ -0,0 +1,432 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+type ChangeType uint8
+
+const (
+	ChangeAdded ChangeType = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+// Change is a service, volume or edge which was added, removed or modified between two graphs
+type Change struct {
+	Type    ChangeType
+	Subject string
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return "+ " + c.Subject
+	case ChangeRemoved:
+		return "- " + c.Subject
+	}
+	return "~ " + c.Subject
+}
+
+// DiffStatus marks the nodes & edges of a graph combining two versions of a project (see DiffGraph)
+type DiffStatus uint8
+
+const (
+	// DiffNone marks the nodes & edges of a graph which is not a combined graph
+	DiffNone DiffStatus = iota
+	DiffUnchanged
+	DiffAdded
+	DiffRemoved
+	DiffChanged
+)
+
+var diffStatusStrings = []string{
+	"none",
+	"unchanged",
+	"added",
+	"removed",
+	"changed",
+}
+
+func (s DiffStatus) String() string {
+	if int(s) < len(diffStatusStrings) {
+		return diffStatusStrings[s]
+	}
+	return diffStatusStrings[DiffNone]
+}
+
+// marker returns the annotation of the text formats for the added, removed & changed nodes & edges
+func (s DiffStatus) marker() string {
+	if s <= DiffUnchanged {
+		return ""
+	}
+	return "[" + s.String() + "]"
+}
+
+// diffPalettes replace the category palettes of the nodes of a combined graph, so that the
+// changes stand out; the categories remain distinguishable by their shapes
+var diffPalettes = map[DiffStatus]Palette{
+	DiffUnchanged: {ColorFill: White, ColorBorder: DarkGrey, ColorFont: DarkGrey},
+	DiffAdded:     {ColorFill: Green, ColorBorder: DarkGreen, ColorFont: White},
+	DiffRemoved:   {ColorFill: Red, ColorBorder: DarkRed, ColorFont: White},
+	DiffChanged:   {ColorFill: Orange, ColorBorder: DarkOrange, ColorFont: White},
+}
+
+// diffColors are the colors of the added, removed & changed edges of a combined graph
+var diffColors = map[DiffStatus]Color{
+	DiffAdded:   Green,
+	DiffRemoved: Red,
+	DiffChanged: Orange,
+}
+
+// Diff compares the nodes & edges of the two graphs (see DiffGraph) and describes the changes,
+// ordered by subject
+func Diff(before, after Graph) []Change {
+	_, changes := diff(before, after)
+	return changes
+}
+
+// DiffGraph combines the two graphs into one where each node & edge is marked as unchanged, added,
+// removed or changed (a new category, display label, image, ports or labels of a service, dependency
+// condition or mount access); the node groups are
+// matched by label, the remaining ones in order (e.g. a single renamed compose file)
+func DiffGraph(before, after Graph) Graph {
+	combined, _ := diff(before, after)
+	return combined
+}
+
+// edgeKey identifies an edge across the two graphs: a dependency by its nodes, a mount by its
+// nodes & target
+type edgeKey struct {
+	kind     EdgeKind
+	from, to NodeID
+	target   string
+}
+
+func diff(before, after Graph) (Graph, []Change) {
+	beforeGroups, afterGroups, labels := matchGroups(before, after)
+
+	var changes []Change
+
+	groups := make([]NodeGroup, len(labels))
+
+	for gi, label := range labels {
+		groups[gi] = NodeGroup{Label: label}
+
+		nodes := make(map[string][2]*Node)
+
+		for bi, g := range beforeGroups {
+			if g == gi {
+				for ni := range before.Groups[bi].Nodes {
+					node := &before.Groups[bi].Nodes[ni]
+					nodes[node.Name] = [2]*Node{node, nodes[node.Name][1]}
+				}
+			}
+		}
+
+		for ai, g := range afterGroups {
+			if g == gi {
+				for ni := range after.Groups[ai].Nodes {
+					node := &after.Groups[ai].Nodes[ni]
+					nodes[node.Name] = [2]*Node{nodes[node.Name][0], node}
+				}
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(nodes)) {
+			b, a := nodes[name][0], nodes[name][1]
+
+			var node Node
+
+			switch {
+			case b == nil:
+				node, node.Diff = *a, DiffAdded
+				changes = append(changes, Change{Type: ChangeAdded, Subject: describe(nodeSubject(node))})
+
+			case a == nil:
+				node, node.Diff = *b, DiffRemoved
+				changes = append(changes, Change{Type: ChangeRemoved, Subject: describe(nodeSubject(node))})
+
+			case len(nodeChanges(*b, *a)) != 0:
+				node, node.Diff = *a, DiffChanged
+
+				subject, _ := nodeSubject(*a)
+				changes = append(changes, Change{Type: ChangeModified, Subject: describe(subject, strings.Join(nodeChanges(*b, *a), ", "))})
+
+			default:
+				node, node.Diff = *a, DiffUnchanged
+			}
+
+			groups[gi].Nodes = append(groups[gi].Nodes, node)
+		}
+	}
+
+	// the edges of both graphs keyed by their nodes within the combined graph
+	beforeEdges := combinedEdges(before, beforeGroups)
+	afterEdges := combinedEdges(after, afterGroups)
+
+	var edges []Edge
+
+	// the nodes are sorted, so are the edges
+	for gi, group := range groups {
+		for _, node := range group.Nodes {
+			from := NodeID{Group: gi, Name: node.Name}
+
+			for _, a := range afterEdges.outgoing[from] {
+				e := afterEdges.edges[a]
+
+				b, ok := beforeEdges.edges[a]
+				switch {
+				case !ok:
+					e.Diff = DiffAdded
+					changes = append(changes, Change{Type: ChangeAdded, Subject: describe(edgeSubject(e))})
+
+				case edgeAttributes(b) != edgeAttributes(e):
+					e.Diff = DiffChanged
+
+					subject, previous := edgeSubject(b)
+					changes = append(changes, Change{Type: ChangeModified, Subject: describeChange(subject, previous, edgeAttributes(e))})
+
+				default:
+					e.Diff = DiffUnchanged
+				}
+
+				edges = append(edges, e)
+			}
+
+			for _, b := range beforeEdges.outgoing[from] {
+				if _, ok := afterEdges.edges[b]; ok {
+					continue
+				}
+
+				e := beforeEdges.edges[b]
+				e.Diff = DiffRemoved
+				changes = append(changes, Change{Type: ChangeRemoved, Subject: describe(edgeSubject(e))})
+
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	// order the changes by subject (ignoring the attributes in parentheses)
+	slices.SortStableFunc(changes, func(x, y Change) int {
+		return cmp.Compare(diffKey(x.Subject), diffKey(y.Subject))
+	})
+
+	combined := NewGraph(groups, edges)
+	combined.Theme = after.Theme
+	combined.Legend = after.Legend
+
+	return combined, changes
+}
+
+// matchGroups returns the index of the combined group of each group of the two graphs, along with
+// the labels of the combined groups: the groups of the second graph, followed by the groups only
+// found in the first one
+func matchGroups(before, after Graph) ([]int, []int, []string) {
+	beforeGroups := make([]int, len(before.Groups))
+	afterGroups := make([]int, len(after.Groups))
+
+	var labels []string
+
+	for ai, group := range after.Groups {
+		afterGroups[ai] = len(labels)
+		labels = append(labels, group.Label)
+	}
+
+	matched := make([]bool, len(after.Groups))
+
+	for bi, group := range before.Groups {
+		beforeGroups[bi] = -1
+
+		for ai := range after.Groups {
+			if !matched[ai] && after.Groups[ai].Label == group.Label {
+				beforeGroups[bi], matched[ai] = ai, true
+				break
+			}
+		}
+	}
+
+	// the remaining groups are matched in order
+	for bi := range before.Groups {
+		if beforeGroups[bi] != -1 {
+			continue
+		}
+
+		if ai := slices.Index(matched, false); ai != -1 {
+			beforeGroups[bi], matched[ai] = ai, true
+			continue
+		}
+
+		beforeGroups[bi] = len(labels)
+		labels = append(labels, before.Groups[bi].Label)
+	}
+
+	return beforeGroups, afterGroups, labels
+}
+
+// keyedEdges are the edges of a graph keyed by their nodes within the combined graph
+type keyedEdges struct {
+	edges    map[edgeKey]Edge
+	outgoing map[NodeID][]edgeKey
+}
+
+func combinedEdges(g Graph, groups []int) keyedEdges {
+	k := keyedEdges{
+		edges:    make(map[edgeKey]Edge),
+		outgoing: make(map[NodeID][]edgeKey),
+	}
+
+	for _, e := range g.Edges {
+		e.From.Group = groups[e.From.Group]
+		e.To.Group = groups[e.To.Group]
+
+		key := edgeKey{kind: e.Kind, from: e.From, to: e.To}
+		if e.Kind == EdgeKindVolumeMount {
+			key.target = e.Target
+		}
+
+		k.edges[key] = e
+		k.outgoing[e.From] = append(k.outgoing[e.From], key)
+	}
+
+	return k
+}
+
+func diffKey(subject string) string {
+	key, _, _ := strings.Cut(subject, " (")
+	return key
+}
+
+// nodeSubject describes the node as a subject (e.g. 'service my-service') & its attributes
+func nodeSubject(node Node) (string, string) {
+	if node.Category == CategoryVolume {
+		return fmt.Sprintf("volume %s", node.Name), ""
+	}
+	return fmt.Sprintf("service %s", node.Name), node.Category.String()
+}
+
+// nodeChanges describes the attributes which differ between the two versions of the node, e.g.
+// 'image nginx:1.25 → nginx:1.27' (a new category is described by its values alone)
+func nodeChanges(b, a Node) []string {
+	var changes []string
+
+	if b.Category != a.Category {
+		changes = append(changes, b.Category.String()+" → "+a.Category.String())
+	}
+
+	// the display label usually follows the labels, which are described instead
+	if b.Label != a.Label && maps.Equal(b.Labels, a.Labels) {
+		changes = append(changes, fmt.Sprintf("display label %s → %s", orNone(b.Label), orNone(a.Label)))
+	}
+
+	if b.Image != a.Image {
+		changes = append(changes, fmt.Sprintf("image %s → %s", orNone(b.Image), orNone(a.Image)))
+	}
+
+	if !slices.Equal(b.Ports, a.Ports) {
+		changes = append(changes, fmt.Sprintf("ports %s → %s", orNone(strings.Join(b.Ports, " ")), orNone(strings.Join(a.Ports, " "))))
+	}
+
+	keys := slices.Sorted(maps.Keys(b.Labels))
+	for key := range a.Labels {
+		if _, ok := b.Labels[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if previous, current := b.Labels[key], a.Labels[key]; previous != current {
+			changes = append(changes, fmt.Sprintf("label %s %s → %s", key, orNone(previous), orNone(current)))
+		}
+	}
+
+	return changes
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// edgeSubject describes the edge as a subject (e.g. 'dependency a -> b') & its attributes
+func edgeSubject(e Edge) (string, string) {
+	if e.Kind == EdgeKindVolumeMount {
+		return fmt.Sprintf("mount %s -> %s:%s", e.From.Name, e.To.Name, e.Target), readWrite(e.ReadOnly)
+	}
+	return fmt.Sprintf("dependency %s -> %s", e.From.Name, e.To.Name), dependencyNotes(e.Condition.String(), e)
+}
+
+func edgeAttributes(e Edge) string {
+	_, attributes := edgeSubject(e)
+	return attributes
+}
+
+// describe formats the subject followed by its attributes in parentheses (if any)
+func describe(subject, attributes string) string {
+	if attributes == "" {
+		return subject
+	}
+	return subject + " (" + attributes + ")"
+}
+
+// describeChange formats the subject followed by its attributes before & after the change
+func describeChange(subject, before, after string) string {
+	return subject + " (" + before + " → " + after + ")"
+}
+
+// diffDecorations colors the added, removed & changed edges of a combined graph
+func diffDecorations(d EdgeDecorations, status DiffStatus) EdgeDecorations {
+	if c, ok := diffColors[status]; ok {
+		d.color = c
+	}
+	return d
+}
+
+// isDiff reports whether the groups belong to a combined graph
+func isDiff(groups []NodeGroup) bool {
+	for _, group := range groups {
+		for _, node := range group.Nodes {
+			if node.Diff != DiffNone {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// diffSamples returns the legend samples of the statuses present in a combined graph
+func diffSamples(g Graph) []legendEdge {
+	present := make(map[DiffStatus]bool)
+
+	for _, group := range g.Groups {
+		for _, node := range group.Nodes {
+			present[node.Diff] = true
+		}
+	}
+
+	for _, e := range g.Edges {
+		present[e.Diff] = true
+	}
+
+	started, _ := dependencyDecorations(compose.ConditionServiceStarted)
+
+	var samples []legendEdge
+
+	for _, status := range []DiffStatus{DiffAdded, DiffRemoved, DiffChanged} {
+		if present[status] {
+			samples = append(samples, legendEdge{label: status.String(), decorations: diffDecorations(started, status)})
+		}
+	}
+
+	return samples
+}
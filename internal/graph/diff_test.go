@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceStarted),
+	})
+
+	after := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Category: CategoryService1},
+			{Name: "my-tool", Category: CategoryTool},
+		},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		dependsOn(0, "my-tool", "my-service", compose.ConditionServiceStarted),
+	})
+
+	changes := Diff(before, after)
+
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{
+		"~ dependency my-service -> my-database (service_started → service_healthy)",
+		"+ dependency my-tool -> my-service (service_started)",
+		"- mount my-database -> my-volume:/data (rw)",
+		"+ service my-tool (tool)",
+		"- volume my-volume",
+	}, lines)
+
+	assert.Empty(t, Diff(before, before))
+}
+
+func TestDiffNodeAttributes(t *testing.T) {
+	before := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "api", Label: "api", Category: CategoryService1, Image: "api:1.0", Ports: []string{"8080:80"}},
+			{Name: "web", Label: "web", Category: CategoryService1, Image: "nginx:1.25", Labels: map[string]string{graphNodeLabel: "web", "team": "front"}},
+		},
+	}}, nil)
+
+	after := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "api", Label: "api", Category: CategoryService1, Image: "api:1.1", Ports: []string{"8080:80"}},
+			{Name: "web", Label: "frontend", Category: CategoryService1, Image: "nginx:1.25", Labels: map[string]string{graphNodeLabel: "frontend", "tier": "edge"}},
+		},
+	}}, nil)
+
+	var lines []string
+	for _, c := range Diff(before, after) {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{
+		"~ service api (image api:1.0 → api:1.1)",
+		"~ service web (label graph.node.label web → frontend, label team front → none, label tier none → edge)",
+	}, lines)
+
+	var nodes []string
+	for _, node := range DiffGraph(before, after).Groups[0].Nodes {
+		nodes = append(nodes, node.Name+" "+node.Diff.String())
+	}
+	assert.Equal(t, []string{"api changed", "web changed"}, nodes)
+
+	// the display label & the ports are compared too
+	after.Groups[0].Nodes[0] = Node{Name: "api", Label: "API", Category: CategoryService1, Image: "api:1.0", Ports: []string{"9090:80"}}
+
+	lines = nil
+	for _, c := range Diff(before, NewGraph(after.Groups, nil)) {
+		lines = append(lines, c.String())
+	}
+	assert.Contains(t, lines, "~ service api (display label api → API, ports 8080:80 → 9090:80)")
+}
+
+func TestDiffGraph(t *testing.T) {
+	before := NewGraph([]NodeGroup{{
+		Label: "compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", true),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceStarted),
+	})
+
+	// the renamed file is matched in order
+	after := NewGraph([]NodeGroup{{
+		Label: "compose.new.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryTool},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		mounts(0, "my-service", "my-volume", "/cache", true),
+	})
+
+	combined := DiffGraph(before, after)
+
+	require.Len(t, combined.Groups, 1)
+	assert.Equal(t, "compose.new.yaml", combined.Groups[0].Label)
+
+	var nodes []string
+	for _, node := range combined.Groups[0].Nodes {
+		nodes = append(nodes, node.Name+" "+node.Diff.String())
+	}
+	assert.Equal(t, []string{"my-database unchanged", "my-service changed", "my-volume unchanged"}, nodes)
+
+	var edges []string
+	for _, e := range combined.Edges {
+		edges = append(edges, e.From.Name+" -> "+e.To.Name+" "+e.Diff.String())
+	}
+	assert.Equal(t, []string{
+		"my-database -> my-volume changed",
+		"my-service -> my-volume added",
+		"my-service -> my-database removed",
+	}, edges)
+
+	// the combined graph is rendered with the status palettes & colors
+	var b strings.Builder
+	require.NoError(t, Print(&b, combined))
+
+	assert.Contains(t, b.String(), `my_service                           [shape = "octagon"    style = "rounded,bold,filled"    fillcolor = "/oranges8/7" color = "/oranges8/8" fontcolor = "white"      label = "my-service"];`)
+	assert.Contains(t, b.String(), `fillcolor = "white"      color = "/greys8/8"  fontcolor = "/greys8/8"  label = "my-database"];`)
+	assert.Contains(t, b.String(), `my_service                             -> my_database                            [style="dashed" color="/orrd8/7"];`)
+	assert.Contains(t, b.String(), `label = "removed"];`)
+
+	b.Reset()
+	require.NoError(t, PrintTree(&b, combined, TreeOptions{}))
+	assert.Equal(t, `compose.new.yaml
+└── my-service [changed]
+    ├── my-volume [ro] [added]
+    └── my-database (started) [removed]
+        └── my-volume [rw] [changed]
+`, b.String())
+
+	b.Reset()
+	require.NoError(t, PrintMermaid(&b, combined))
+	assert.Contains(t, b.String(), ":::changed\n")
+	assert.Contains(t, b.String(), "classDef changed fill:#d94801")
+	assert.NotContains(t, b.String(), "classDef tool")
+}
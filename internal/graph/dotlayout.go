@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultFontName is the font of the dot-graph unless overridden by the layout
+const defaultFontName = "arial"
+
+var (
+	// rankDirs are the directions in which graphviz arranges the ranks
+	rankDirs = []string{"TB", "LR", "BT", "RL"}
+
+	// splines are the graphviz edge routing modes
+	splines = []string{"spline", "line", "polyline", "ortho", "curved", "none"}
+)
+
+// DOTLayout holds the graphviz attributes which control the arrangement of the dot-graph;
+// the zero values leave the graphviz defaults in place
+type DOTLayout struct {
+	// RankDir is the direction of the ranks: TB (top to bottom), LR, BT or RL
+	RankDir string
+
+	// NodeSep & RankSep are the minimum spaces (in inches) between the nodes of a rank & between the ranks
+	NodeSep float64
+	RankSep float64
+
+	// Splines is the edge routing mode: spline, line, polyline, ortho, curved or none
+	Splines string
+
+	// Concentrate merges the edges running in parallel
+	Concentrate bool
+
+	// FontName & FontSize (in points) apply to the graph, the nodes & the edges (arial if empty)
+	FontName string
+	FontSize float64
+
+	// VolumesSink pins the volumes to the bottom rank of their cluster
+	VolumesSink bool
+}
+
+// Validate checks the layout attributes against the values supported by graphviz
+func (l DOTLayout) Validate() error {
+	if l.RankDir != "" && !slices.Contains(rankDirs, strings.ToUpper(l.RankDir)) {
+		return fmt.Errorf("invalid rankdir '%s' (available: %s)", l.RankDir, strings.Join(rankDirs, ", "))
+	}
+
+	if l.Splines != "" && !slices.Contains(splines, strings.ToLower(l.Splines)) {
+		return fmt.Errorf("invalid splines '%s' (available: %s)", l.Splines, strings.Join(splines, ", "))
+	}
+
+	for _, attribute := range []struct {
+		name  string
+		value float64
+	}{
+		{"nodesep", l.NodeSep},
+		{"ranksep", l.RankSep},
+		{"fontsize", l.FontSize},
+	} {
+		if attribute.value < 0 {
+			return fmt.Errorf("invalid %s '%s': must not be negative", attribute.name, formatFloat(attribute.value))
+		}
+	}
+
+	return nil
+}
+
+// graphAttributes returns the attributes of the 'graph' statement of the dot-graph
+func (l DOTLayout) graphAttributes() string {
+	attributes := []string{l.fontAttributes()}
+
+	if l.RankDir != "" {
+		attributes = append(attributes, fmt.Sprintf("rankdir = %q", strings.ToUpper(l.RankDir)))
+	}
+
+	if l.NodeSep != 0 {
+		attributes = append(attributes, fmt.Sprintf("nodesep = %q", formatFloat(l.NodeSep)))
+	}
+
+	if l.RankSep != 0 {
+		attributes = append(attributes, fmt.Sprintf("ranksep = %q", formatFloat(l.RankSep)))
+	}
+
+	if l.Splines != "" {
+		attributes = append(attributes, fmt.Sprintf("splines = %q", strings.ToLower(l.Splines)))
+	}
+
+	if l.Concentrate {
+		attributes = append(attributes, `concentrate = "true"`)
+	}
+
+	return strings.Join(attributes, " ")
+}
+
+// fontAttributes returns the font attributes shared by the graph, the nodes & the edges
+func (l DOTLayout) fontAttributes() string {
+	attributes := fmt.Sprintf("fontname = %q", cmp.Or(l.FontName, defaultFontName))
+
+	if l.FontSize != 0 {
+		attributes += fmt.Sprintf(" fontsize = %q", formatFloat(l.FontSize))
+	}
+
+	return attributes
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDOTLayoutValidate(t *testing.T) {
+	assert.NoError(t, DOTLayout{}.Validate())
+	assert.NoError(t, DOTLayout{RankDir: "lr", Splines: "Ortho", NodeSep: 0.5}.Validate())
+
+	assert.EqualError(t, DOTLayout{RankDir: "up"}.Validate(), "invalid rankdir 'up' (available: TB, LR, BT, RL)")
+	assert.EqualError(t, DOTLayout{Splines: "wavy"}.Validate(), "invalid splines 'wavy' (available: spline, line, polyline, ortho, curved, none)")
+	assert.EqualError(t, DOTLayout{RankSep: -1}.Validate(), "invalid ranksep '-1': must not be negative")
+}
+
+func TestDOTLayoutAttributes(t *testing.T) {
+	// the defaults leave the graphviz defaults in place
+	assert.Equal(t, `fontname = "arial"`, DOTLayout{}.graphAttributes())
+	assert.Equal(t, `fontname = "arial"`, DOTLayout{}.fontAttributes())
+
+	l := DOTLayout{
+		RankDir:     "lr",
+		NodeSep:     0.25,
+		RankSep:     1,
+		Splines:     "ortho",
+		Concentrate: true,
+		FontName:    "helvetica",
+		FontSize:    10.5,
+	}
+
+	assert.Equal(t, `fontname = "helvetica" fontsize = "10.5" rankdir = "LR" nodesep = "0.25" ranksep = "1" splines = "ortho" concentrate = "true"`, l.graphAttributes())
+	assert.Equal(t, `fontname = "helvetica" fontsize = "10.5"`, l.fontAttributes())
+}
+
+func TestPrintDOTLayout(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+			{Name: "other-volume", Label: "other-volume", Category: CategoryVolume},
+		},
+	}}, nil)
+
+	var b strings.Builder
+	require.NoError(t, PrintDOT(&b, g, DOTOptions{Layout: DOTLayout{RankDir: "LR", VolumesSink: true}}))
+
+	assert.Contains(t, b.String(), `  graph [fontname = "arial" rankdir = "LR"];`+"\n")
+	assert.Contains(t, b.String(), `    { rank = "sink"; my_volume; other_volume; }`+"\n")
+
+	// the legend is not pinned
+	assert.Equal(t, 1, strings.Count(b.String(), "rank = "))
+
+	assert.Error(t, PrintDOT(&b, g, DOTOptions{Layout: DOTLayout{RankDir: "up"}}))
+}
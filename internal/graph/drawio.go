@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// drawio (diagrams.net) file structure, see https://www.drawio.com/doc/faq/drawio-xml-format
+type drawioFile struct {
+	XMLName xml.Name      `xml:"mxfile"`
+	Host    string        `xml:"host,attr"`
+	Diagram drawioDiagram `xml:"diagram"`
+}
+
+type drawioDiagram struct {
+	ID    string      `xml:"id,attr"`
+	Name  string      `xml:"name,attr"`
+	Model drawioModel `xml:"mxGraphModel"`
+}
+
+type drawioModel struct {
+	Grid       int          `xml:"grid,attr"`
+	PageWidth  int          `xml:"pageWidth,attr"`
+	PageHeight int          `xml:"pageHeight,attr"`
+	Cells      []drawioCell `xml:"root>mxCell"`
+}
+
+type drawioCell struct {
+	ID       string          `xml:"id,attr"`
+	Value    string          `xml:"value,attr,omitempty"`
+	Style    string          `xml:"style,attr,omitempty"`
+	Vertex   string          `xml:"vertex,attr,omitempty"`
+	Edge     string          `xml:"edge,attr,omitempty"`
+	Parent   string          `xml:"parent,attr,omitempty"`
+	Source   string          `xml:"source,attr,omitempty"`
+	Target   string          `xml:"target,attr,omitempty"`
+	Geometry *drawioGeometry `xml:"mxGeometry"`
+}
+
+type drawioGeometry struct {
+	X        int           `xml:"x,attr,omitempty"`
+	Y        int           `xml:"y,attr,omitempty"`
+	Width    int           `xml:"width,attr,omitempty"`
+	Height   int           `xml:"height,attr,omitempty"`
+	Relative string        `xml:"relative,attr,omitempty"`
+	As       string        `xml:"as,attr"`
+	Points   []drawioPoint `xml:"mxPoint"`
+}
+
+// drawioPoint is an end of an edge which is not connected to any cell
+type drawioPoint struct {
+	X  int    `xml:"x,attr"`
+	Y  int    `xml:"y,attr"`
+	As string `xml:"as,attr"`
+}
+
+// PrintDrawIO will print the given graph as a draw.io (diagrams.net) diagram
+func PrintDrawIO(w io.Writer, g Graph) error {
+	// the legend is laid out as the last group
+	l := computeLegendLayout(g)
+
+	cells := []drawioCell{
+		{ID: "0"},
+		{ID: "1", Parent: "0"},
+	}
+
+	for gi, group := range l.groups {
+		cells = append(cells, drawioCell{
+			ID:     drawioGroupID(gi),
+			Value:  group.label,
+			Style:  drawioGroupStyle(),
+			Vertex: "1",
+			Parent: "1",
+			Geometry: &drawioGeometry{
+				X:      group.x,
+				Y:      group.y,
+				Width:  group.width,
+				Height: group.height,
+				As:     "geometry",
+			},
+		})
+
+		for ni, node := range group.nodes {
+			d, err := g.Theme.nodeDecorations(node.Node)
+			if err != nil {
+				return err
+			}
+
+			cells = append(cells, drawioCell{
+				ID:     nodeID(layoutRef{group: gi, node: ni}),
+				Value:  node.Label,
+				Style:  drawioNodeStyle(d, l.isLegend(gi)),
+				Vertex: "1",
+				Parent: drawioGroupID(gi),
+				Geometry: &drawioGeometry{
+					X:      node.x,
+					Y:      node.y,
+					Width:  node.width,
+					Height: node.height,
+					As:     "geometry",
+				},
+			})
+		}
+	}
+
+	for i, e := range l.edges {
+		d, err := edgeDecorations(e.Edge)
+		if err != nil {
+			return err
+		}
+
+		cells = append(cells, drawioCell{
+			ID:       fmt.Sprintf("edge_%d", i),
+			Style:    drawioEdgeStyle(d),
+			Edge:     "1",
+			Parent:   "1",
+			Source:   nodeID(e.from),
+			Target:   nodeID(e.to),
+			Geometry: &drawioGeometry{Relative: "1", As: "geometry"},
+		})
+	}
+
+	// the legend edge samples are unconnected edges followed by their labels
+	for i, sample := range l.samples {
+		cells = append(cells, drawioCell{
+			ID:     fmt.Sprintf("legend_edge_%d", i),
+			Style:  drawioEdgeStyle(sample.decorations),
+			Edge:   "1",
+			Parent: "1",
+			Geometry: &drawioGeometry{
+				Relative: "1",
+				As:       "geometry",
+				Points: []drawioPoint{
+					{X: sample.x, Y: sample.y, As: "sourcePoint"},
+					{X: sample.x + layoutSampleWidth, Y: sample.y, As: "targetPoint"},
+				},
+			},
+		}, drawioCell{
+			ID:     fmt.Sprintf("legend_edge_%d_label", i),
+			Value:  sample.label,
+			Style:  drawioSampleLabelStyle(),
+			Vertex: "1",
+			Parent: "1",
+			Geometry: &drawioGeometry{
+				X:      sample.x + layoutSampleWidth,
+				Y:      sample.y - layoutSampleHeight/2,
+				Width:  textWidth(sample.label, 0),
+				Height: layoutSampleHeight,
+				As:     "geometry",
+			},
+		})
+	}
+
+	file := drawioFile{
+		Host: "docker-compose-graph",
+		Diagram: drawioDiagram{
+			ID:   "compose",
+			Name: "compose",
+			Model: drawioModel{
+				Grid:       1,
+				PageWidth:  l.width,
+				PageHeight: l.height,
+				Cells:      cells,
+			},
+		},
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("could not encode draw.io diagram: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("could not write draw.io diagram: %w", err)
+	}
+
+	return nil
+}
+
+func drawioGroupID(group int) string {
+	return fmt.Sprintf("cluster_%d", group)
+}
+
+// drawioGroupStyle mirrors the style of the dot-graph subgraph clusters
+func drawioGroupStyle() string {
+	return joinDrawioStyle(
+		[]string{"rounded=1", "container=1", "collapsible=0", "fillColor=none", "verticalAlign=top", "fontFamily=Arial", "fontStyle=1"},
+		drawioStyles([]Style{Rounded, Bold, Dashed}),
+		[]string{"strokeColor=" + DarkGrey.Hex()},
+	)
+}
+
+// drawioNodeStyle translates the node decorations into a draw.io style string
+func drawioNodeStyle(d Decorations, small bool) string {
+	var shape []string
+
+	switch d.shape {
+	case Cylinder:
+		shape = []string{"shape=cylinder3", "boundedLbl=1", "backgroundOutline=1", "size=8"}
+	case Octagon:
+		shape = []string{"shape=mxgraph.basic.octagon2", "dx=8"}
+	case Note:
+		shape = []string{"shape=note", "size=12"}
+	case Diamond:
+		shape = []string{"rhombus"}
+	}
+
+	fontSize := "fontSize=12"
+	if small {
+		fontSize = "fontSize=9"
+	}
+
+	fill := "fillColor=none"
+	if slices.Contains(d.styles, Filled) {
+		fill = "fillColor=" + d.palette.ColorFill.Hex()
+	}
+
+	return joinDrawioStyle(
+		shape,
+		drawioStyles(d.styles),
+		[]string{
+			"whiteSpace=wrap",
+			fill,
+			"strokeColor=" + d.palette.ColorBorder.Hex(),
+			"fontColor=" + d.palette.ColorFont.Hex(),
+			"fontFamily=Arial",
+			fontSize,
+		},
+	)
+}
+
+// drawioEdgeStyle translates the edge decorations into a draw.io style string
+func drawioEdgeStyle(d EdgeDecorations) string {
+	arrow := []string{"endArrow=classic", "endFill=1"}
+	if d.arrowhead == ArrowDiamond {
+		arrow = []string{"endArrow=diamond", "endFill=1", "endSize=10"}
+	}
+
+	if d.arrowtail == ArrowDot {
+		arrow = append(arrow, "startArrow=oval", "startFill=1")
+	}
+
+	return joinDrawioStyle(
+		[]string{"edgeStyle=orthogonalEdgeStyle", "rounded=1", "strokeColor=" + d.strokeColor().Hex()},
+		drawioStyles(d.styles),
+		arrow,
+	)
+}
+
+// drawioSampleLabelStyle is the style of the labels of the legend edge samples
+func drawioSampleLabelStyle() string {
+	return joinDrawioStyle(
+		[]string{"text", "align=left", "verticalAlign=middle", "spacingLeft=6"},
+		[]string{"fontColor=" + DarkGrey.Hex(), "fontFamily=Arial", "fontSize=9"},
+	)
+}
+
+// drawioStyles translates the dot-graph styles into draw.io style properties
+func drawioStyles(styles []Style) []string {
+	var properties []string
+
+	for _, s := range styles {
+		switch s {
+		case Rounded:
+			properties = append(properties, "rounded=1")
+		case Bold:
+			properties = append(properties, "strokeWidth=2")
+		case Dashed:
+			properties = append(properties, "dashed=1")
+		case Dotted:
+			properties = append(properties, "dashed=1", "dashPattern=1 2")
+		}
+	}
+
+	return properties
+}
+
+// joinDrawioStyle joins the style properties, dropping the duplicates
+func joinDrawioStyle(parts ...[]string) string {
+	var properties []string
+
+	for _, part := range parts {
+		for _, p := range part {
+			if !slices.Contains(properties, p) {
+				properties = append(properties, p)
+			}
+		}
+	}
+
+	return strings.Join(properties, ";") + ";"
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintDrawIO(t *testing.T) {
+	var b strings.Builder
+
+	err := PrintDrawIO(&b, NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{
+			Name:     "my-database",
+			Label:    "my-database",
+			Category: CategoryDatabase,
+		}, {
+			Name:     "my-service",
+			Label:    "my <service>",
+			Category: CategoryService1,
+		}},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+	}))
+	require.NoError(t, err)
+
+	out := b.String()
+
+	assert.True(t, strings.HasPrefix(out, `<mxfile host="docker-compose-graph">`))
+	assert.Contains(t, out, `<mxCell id="cluster_0" value="docker-compose.yaml"`)
+	assert.Contains(t, out, `<mxCell id="cluster_1" value="Legend"`)
+	assert.Contains(t, out, `<mxCell id="node_0_1" value="my &lt;service&gt;" style="rounded=1;strokeWidth=2;whiteSpace=wrap;fillColor=#2171b5;strokeColor=#084594;fontColor=#ffffff;fontFamily=Arial;fontSize=12;" vertex="1" parent="cluster_0">`)
+	assert.Contains(t, out, `<mxCell id="edge_0" style="edgeStyle=orthogonalEdgeStyle;rounded=1;strokeColor=#252525;strokeWidth=2;endArrow=diamond;endFill=1;endSize=10;" edge="1" parent="1" source="node_0_1" target="node_0_0">`)
+}
+
+func TestDrawIONodeStyle(t *testing.T) {
+	assert.Equal(
+		t,
+		"shape=cylinder3;boundedLbl=1;backgroundOutline=1;size=8;rounded=1;strokeWidth=2;whiteSpace=wrap;fillColor=#238b45;strokeColor=#005824;fontColor=#ffffff;fontFamily=Arial;fontSize=9;",
+		drawioNodeStyle(categoryDecorations[CategoryDatabase], true),
+	)
+	assert.Equal(
+		t,
+		"shape=note;size=12;strokeWidth=2;whiteSpace=wrap;fillColor=#525252;strokeColor=#252525;fontColor=#ffffff;fontFamily=Arial;fontSize=12;",
+		drawioNodeStyle(categoryDecorations[CategoryScript], false),
+	)
+}
+
+func TestDrawIOEdgeStyle(t *testing.T) {
+	assert.Equal(
+		t,
+		"edgeStyle=orthogonalEdgeStyle;rounded=1;strokeColor=#252525;strokeWidth=2;dashed=1;endArrow=classic;endFill=1;",
+		drawioEdgeStyle(mountDecorations(false)),
+	)
+
+	started, _ := dependencyDecorations(compose.ConditionServiceStarted)
+
+	assert.Equal(
+		t,
+		"edgeStyle=orthogonalEdgeStyle;rounded=1;strokeColor=#969696;dashed=1;dashPattern=1 2;endArrow=classic;endFill=1;startArrow=oval;startFill=1;",
+		drawioEdgeStyle(restartDecorations(optionalDecorations(started))),
+	)
+}
@@ -0,0 +1,326 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+// Graph is the model consumed by the renderers & analyses: node groups (one
+// per compose file) connected by typed edges
+type Graph struct {
+	Groups []NodeGroup
+	Edges  []Edge
+
+	// Theme decorates the nodes by category (DefaultTheme if nil)
+	Theme Theme
+
+	// Legend places the legend of the dot, svg, draw.io & html renderers
+	Legend Legend
+
+	// indexes into the slices above
+	nodes    map[NodeID]nodeIndex
+	outgoing map[NodeID][]int
+	incoming map[NodeID][]int
+}
+
+// Source is a parsed compose file along with the label of its node group
+type Source struct {
+	Label string
+	File  compose.File
+}
+
+// NodeID identifies a node by the index of its group & its name (names may repeat across groups)
+type NodeID struct {
+	Group int
+	Name  string
+}
+
+type nodeIndex struct {
+	group int
+	node  int
+}
+
+type EdgeKind uint8
+
+const (
+	EdgeKindUnknown EdgeKind = iota
+	EdgeKindDependency
+	EdgeKindVolumeMount
+)
+
+var edgeKindStrings = []string{
+	"unknown",
+	"dependency",
+	"volume_mount",
+}
+
+func (k EdgeKind) String() string {
+	if int(k) < len(edgeKindStrings) {
+		return edgeKindStrings[k]
+	}
+	return edgeKindStrings[EdgeKindUnknown]
+}
+
+// Edge is a directed connection from a dependent node to the node it depends on;
+// the attributes which are relevant depend on the kind of the edge
+type Edge struct {
+	Kind EdgeKind
+	From NodeID
+	To   NodeID
+
+	// EdgeKindDependency
+	Condition compose.Condition
+	Required  bool
+	Restart   bool
+
+	// EdgeKindVolumeMount
+	Target   string
+	ReadOnly bool
+
+	// Diff marks the edges of a combined graph (see DiffGraph)
+	Diff DiffStatus
+}
+
+// New builds the graph from the given compose files, one node group per file
+func New(sources ...Source) Graph {
+	var groups []NodeGroup
+
+	for _, source := range sources {
+		groups = append(groups, NodeGroup{
+			Label: source.Label,
+			Nodes: NodesFromFile(source.File),
+		})
+	}
+
+	var edges []Edge
+
+	for gi, group := range groups {
+		services := sources[gi].File.Services
+
+		// nodes are sorted to achieve a reproducible output, so are the edges
+		for _, node := range group.Nodes {
+			service, ok := services[node.Name]
+			if !ok {
+				continue // volume
+			}
+
+			from := NodeID{Group: gi, Name: node.Name}
+
+			for _, dependency := range service.ServiceDependencies {
+				edges = append(edges, Edge{
+					Kind:      EdgeKindDependency,
+					From:      from,
+					To:        resolve(groups, gi, dependency.On),
+					Condition: dependency.Condition,
+					Required:  dependency.Required,
+					Restart:   dependency.Restart,
+				})
+			}
+
+			for _, v := range service.VolumeMounts {
+				// we only care about type "volume" for now
+				if v.Type != compose.VolumeTypeVolume {
+					continue
+				}
+				edges = append(edges, Edge{
+					Kind:     EdgeKindVolumeMount,
+					From:     from,
+					To:       resolve(groups, gi, v.Source),
+					Target:   v.Target,
+					ReadOnly: v.ReadOnly,
+				})
+			}
+		}
+	}
+
+	return NewGraph(groups, edges)
+}
+
+// NewGraph constructs the graph from the given node groups & edges, indexing the edges in both directions
+func NewGraph(groups []NodeGroup, edges []Edge) Graph {
+	g := Graph{
+		Groups:   groups,
+		Edges:    edges,
+		nodes:    make(map[NodeID]nodeIndex),
+		outgoing: make(map[NodeID][]int),
+		incoming: make(map[NodeID][]int),
+	}
+
+	for gi, group := range groups {
+		for ni, node := range group.Nodes {
+			g.nodes[NodeID{Group: gi, Name: node.Name}] = nodeIndex{group: gi, node: ni}
+		}
+	}
+
+	for i, e := range edges {
+		g.outgoing[e.From] = append(g.outgoing[e.From], i)
+		g.incoming[e.To] = append(g.incoming[e.To], i)
+	}
+
+	return g
+}
+
+// resolve finds the node with the given name, preferring the given group; names which
+// cannot be found (e.g. services defined in another file that was not provided) are
+// attributed to the given group
+func resolve(groups []NodeGroup, group int, name string) NodeID {
+	candidates := []int{group}
+	for gi := range groups {
+		candidates = append(candidates, gi)
+	}
+
+	for _, gi := range candidates {
+		for _, node := range groups[gi].Nodes {
+			if node.Name == name {
+				return NodeID{Group: gi, Name: name}
+			}
+		}
+	}
+
+	return NodeID{Group: group, Name: name}
+}
+
+// Node returns the node with the given id
+func (g Graph) Node(id NodeID) (Node, bool) {
+	i, ok := g.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return g.Groups[i.group].Nodes[i.node], true
+}
+
+// Outgoing returns the edges from the given node to its dependencies
+func (g Graph) Outgoing(id NodeID) []Edge {
+	return g.collect(g.outgoing[id])
+}
+
+// Incoming returns the edges from the dependents of the given node
+func (g Graph) Incoming(id NodeID) []Edge {
+	return g.collect(g.incoming[id])
+}
+
+func (g Graph) collect(indexes []int) []Edge {
+	edges := make([]Edge, 0, len(indexes))
+	for _, i := range indexes {
+		edges = append(edges, g.Edges[i])
+	}
+	return edges
+}
+
+// Related returns the given nodes along with their dependencies & the volumes they mount, and with
+// their dependents (or the services mounting them), each followed transitively in its direction
+func (g Graph) Related(ids ...NodeID) map[NodeID]bool {
+	related := make(map[NodeID]bool)
+
+	for _, next := range []func(NodeID) []Edge{g.Outgoing, g.Incoming} {
+		visited := make(map[NodeID]bool)
+		queue := slices.Clone(ids)
+
+		for len(queue) != 0 {
+			id := queue[0]
+			queue = queue[1:]
+
+			if visited[id] {
+				continue
+			}
+			visited[id], related[id] = true, true
+
+			for _, e := range next(id) {
+				if e.From == id {
+					queue = append(queue, e.To)
+				} else {
+					queue = append(queue, e.From)
+				}
+			}
+		}
+	}
+
+	return related
+}
+
+// Filter returns a copy of the graph with only the nodes for which keep returns true, along
+// with the edges between them; edges to nodes missing from the original graph are preserved
+func (g Graph) Filter(keep func(id NodeID, node Node) bool) Graph {
+	groups := make([]NodeGroup, len(g.Groups))
+	removed := make(map[NodeID]bool)
+
+	for gi, group := range g.Groups {
+		groups[gi] = NodeGroup{Label: group.Label}
+
+		for _, node := range group.Nodes {
+			id := NodeID{Group: gi, Name: node.Name}
+
+			if !keep(id, node) {
+				removed[id] = true
+				continue
+			}
+			groups[gi].Nodes = append(groups[gi].Nodes, node)
+		}
+	}
+
+	var edges []Edge
+
+	for _, e := range g.Edges {
+		if removed[e.From] || removed[e.To] {
+			continue
+		}
+		edges = append(edges, e)
+	}
+
+	filtered := NewGraph(groups, edges)
+	filtered.Theme = g.Theme
+	filtered.Legend = g.Legend
+
+	return filtered
+}
+
+// withLegend returns a copy of the graph with the legend appended as the last node group (or the
+// legend alone, or the graph alone) according to the legend placement, along with the edge samples
+// of the legend
+func (g Graph) withLegend() (Graph, []legendEdge) {
+	var groups []NodeGroup
+	var edges []Edge
+
+	if g.Legend != LegendOnly {
+		groups = append(groups, g.Groups...)
+		edges = g.Edges
+	}
+
+	var samples []legendEdge
+
+	if g.Legend != LegendHidden {
+		groups = append(groups, legendGroup(g.Groups))
+		samples = legendSamples(g)
+	}
+
+	withLegend := NewGraph(groups, edges)
+	withLegend.Theme = g.Theme
+	withLegend.Legend = g.Legend
+
+	return withLegend, samples
+}
+
+// edgeDecorations returns the decorations for the given edge
+func edgeDecorations(e Edge) (EdgeDecorations, error) {
+	switch e.Kind {
+	case EdgeKindDependency:
+		d, ok := dependencyDecorations(e.Condition)
+		if !ok {
+			return EdgeDecorations{}, fmt.Errorf("unexpected dependency condition %q", e.Condition)
+		}
+		if !e.Required {
+			d = optionalDecorations(d)
+		}
+		if e.Restart {
+			d = restartDecorations(d)
+		}
+		return diffDecorations(d, e.Diff), nil
+
+	case EdgeKindVolumeMount:
+		return diffDecorations(mountDecorations(e.ReadOnly), e.Diff), nil
+	}
+
+	return EdgeDecorations{}, fmt.Errorf("unexpected edge kind %q", e.Kind)
+}
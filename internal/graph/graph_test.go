@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dependsOn is a shorthand for a dependency edge between two nodes of the same group
+func dependsOn(group int, from, to string, condition compose.Condition) Edge {
+	return Edge{
+		Kind:      EdgeKindDependency,
+		From:      NodeID{Group: group, Name: from},
+		To:        NodeID{Group: group, Name: to},
+		Condition: condition,
+		Required:  true,
+	}
+}
+
+// mounts is a shorthand for a volume mount edge between two nodes of the same group
+func mounts(group int, from, volume, target string, readOnly bool) Edge {
+	return Edge{
+		Kind:     EdgeKindVolumeMount,
+		From:     NodeID{Group: group, Name: from},
+		To:       NodeID{Group: group, Name: volume},
+		Target:   target,
+		ReadOnly: readOnly,
+	}
+}
+
+func TestNew(t *testing.T) {
+	g := New(Source{
+		Label: "docker-compose-1.yaml",
+		File: compose.File{
+			Services: map[string]compose.Service{
+				"my-service": {
+					ServiceDependencies: []compose.ServiceDependency{
+						{On: "my-database", Condition: compose.ConditionServiceHealthy, Required: true},
+						{On: "my-tool", Condition: compose.ConditionServiceStarted, Required: true},
+					},
+					VolumeMounts: []compose.VolumeMount{
+						{Type: compose.VolumeTypeVolume, Source: "my-volume", Target: "/data", ReadOnly: true},
+						{Type: compose.VolumeTypeBind, Source: "/host", Target: "/host"},
+					},
+				},
+				"my-database": {
+					VolumeMounts: []compose.VolumeMount{
+						{Type: compose.VolumeTypeVolume, Source: "my-volume", Target: "/var/lib/data"},
+					},
+				},
+			},
+			Volumes: []string{"my-volume"},
+		},
+	}, Source{
+		Label: "docker-compose-2.yaml",
+		File: compose.File{
+			Services: map[string]compose.Service{
+				"my-tool": {},
+			},
+		},
+	})
+
+	require.Len(t, g.Groups, 2)
+	assert.Equal(t, "docker-compose-1.yaml", g.Groups[0].Label)
+	assert.Equal(t, "docker-compose-2.yaml", g.Groups[1].Label)
+
+	// bind mounts are ignored; edges follow the order of the (sorted) nodes
+	assert.Equal(t, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		{
+			Kind:      EdgeKindDependency,
+			From:      NodeID{Group: 0, Name: "my-service"},
+			To:        NodeID{Group: 1, Name: "my-tool"}, // resolved across groups
+			Condition: compose.ConditionServiceStarted,
+			Required:  true,
+		},
+		mounts(0, "my-service", "my-volume", "/data", true),
+	}, g.Edges)
+
+	// adjacency in both directions
+	assert.Equal(t, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/data", false),
+		mounts(0, "my-service", "my-volume", "/data", true),
+	}, g.Incoming(NodeID{Group: 0, Name: "my-volume"}))
+	assert.Len(t, g.Outgoing(NodeID{Group: 0, Name: "my-service"}), 3)
+	assert.Empty(t, g.Outgoing(NodeID{Group: 0, Name: "my-volume"}))
+
+	node, ok := g.Node(NodeID{Group: 1, Name: "my-tool"})
+	require.True(t, ok)
+	assert.Equal(t, CategoryTool, node.Category)
+
+	_, ok = g.Node(NodeID{Group: 0, Name: "my-tool"})
+	assert.False(t, ok)
+}
+
+func TestNewMissingDependency(t *testing.T) {
+	g := New(Source{
+		Label: "docker-compose.yaml",
+		File: compose.File{
+			Services: map[string]compose.Service{
+				"my-service": {
+					ServiceDependencies: []compose.ServiceDependency{
+						{On: "elsewhere", Condition: compose.ConditionServiceStarted, Required: true},
+					},
+				},
+			},
+		},
+	})
+
+	// dangling edges are kept & attributed to the dependent's group
+	assert.Equal(t, []Edge{
+		dependsOn(0, "my-service", "elsewhere", compose.ConditionServiceStarted),
+	}, g.Edges)
+}
+
+func TestEdgeDecorations(t *testing.T) {
+	d, err := edgeDecorations(dependsOn(0, "a", "b", compose.ConditionServiceHealthy))
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDecorations{styles: []Style{Bold}, arrowhead: ArrowDiamond}, d)
+
+	d, err = edgeDecorations(mounts(0, "a", "v", "/v", true))
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDecorations{styles: []Style{Dashed}}, d)
+
+	// optional dependencies are dotted & faded, restarts are marked at the dependent end
+	optional := dependsOn(0, "a", "b", compose.ConditionServiceStarted)
+	optional.Required = false
+	optional.Restart = true
+
+	d, err = edgeDecorations(optional)
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDecorations{styles: []Style{Dotted}, arrowtail: ArrowDot, color: LightGrey}, d)
+
+	optional.Condition = compose.ConditionServiceHealthy
+
+	d, err = edgeDecorations(optional)
+	require.NoError(t, err)
+	assert.Equal(t, EdgeDecorations{styles: []Style{Bold, Dotted}, arrowhead: ArrowDiamond, arrowtail: ArrowDot, color: LightGrey}, d)
+
+	_, err = edgeDecorations(dependsOn(0, "a", "b", compose.ConditionUnknown))
+	assert.Error(t, err)
+
+	_, err = edgeDecorations(Edge{})
+	assert.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Category: CategoryService1},
+			{Name: "my-tool", Category: CategoryTool},
+			{Name: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		dependsOn(0, "my-service", "my-tool", compose.ConditionServiceStarted),
+		dependsOn(0, "my-service", "my-missing", compose.ConditionServiceStarted),
+	})
+	g.Theme = MonochromeTheme
+
+	filtered := g.Filter(func(_ NodeID, node Node) bool {
+		return node.Category != CategoryTool
+	})
+
+	require.Len(t, filtered.Groups, 1)
+	assert.Len(t, filtered.Groups[0].Nodes, 3)
+	assert.Equal(t, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		dependsOn(0, "my-service", "my-missing", compose.ConditionServiceStarted),
+	}, filtered.Edges)
+	assert.Len(t, filtered.Outgoing(NodeID{Group: 0, Name: "my-service"}), 2)
+	assert.Equal(t, MonochromeTheme, filtered.Theme)
+
+	// the original graph is left untouched
+	assert.Len(t, g.Groups[0].Nodes, 4)
+	assert.Len(t, g.Edges, 4)
+}
+
+func TestRelated(t *testing.T) {
+	id := func(name string) NodeID { return NodeID{Group: 0, Name: name} }
+
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "proxy", Category: CategoryTool},
+			{Name: "web", Category: CategoryService1},
+			{Name: "api", Category: CategoryService1},
+			{Name: "db", Category: CategoryDatabase},
+			{Name: "worker", Category: CategoryService1},
+			{Name: "data", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "proxy", "web", compose.ConditionServiceStarted),
+		dependsOn(0, "web", "api", compose.ConditionServiceStarted),
+		dependsOn(0, "api", "db", compose.ConditionServiceHealthy),
+		dependsOn(0, "worker", "db", compose.ConditionServiceHealthy),
+		mounts(0, "db", "data", "/data", false),
+	})
+
+	// the dependencies & dependents are followed, but not the other dependents of the dependencies
+	assert.Equal(t, map[NodeID]bool{
+		id("proxy"): true,
+		id("web"):   true,
+		id("api"):   true,
+		id("db"):    true,
+		id("data"):  true,
+	}, g.Related(id("web")))
+
+	// a volume relates to the services mounting it & their dependents
+	assert.Equal(t, map[NodeID]bool{
+		id("proxy"):  true,
+		id("web"):    true,
+		id("api"):    true,
+		id("db"):     true,
+		id("worker"): true,
+		id("data"):   true,
+	}, g.Related(id("data")))
+
+	assert.Empty(t, g.Related())
+}
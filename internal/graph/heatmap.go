@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric is a per-service metric the dot format can color the services by
+type Metric uint8
+
+const (
+	MetricNone Metric = iota
+	MetricFanIn
+	MetricFanOut
+)
+
+var metricStrings = []string{
+	"none",
+	"fan-in",
+	"fan-out",
+}
+
+func (m Metric) String() string {
+	if int(m) < len(metricStrings) {
+		return metricStrings[m]
+	}
+	return metricStrings[MetricNone]
+}
+
+// ParseMetric returns the metric with the given name ("none", "fan-in" or "fan-out")
+func ParseMetric(name string) (Metric, error) {
+	for i, s := range metricStrings {
+		if s == name {
+			return Metric(i), nil
+		}
+	}
+	return MetricNone, fmt.Errorf("unknown metric '%s' (available: %s)", name, strings.Join(metricStrings, ", "))
+}
+
+func (m Metric) value(s ServiceStats) int {
+	switch m {
+	case MetricFanIn:
+		return s.FanIn
+	case MetricFanOut:
+		return s.FanOut
+	}
+	return 0
+}
+
+// heatLevels is the number of colors of the heatmap scheme, the lightest one marking the lowest value
+const heatLevels = 9
+
+// heatmap holds the heat level (1 to heatLevels) of each service by the given metric, along with
+// the range of the metric
+type heatmap struct {
+	metric   Metric
+	levels   map[NodeID]int
+	min, max int
+}
+
+func newHeatmap(g Graph, metric Metric) heatmap {
+	h := heatmap{metric: metric, levels: make(map[NodeID]int)}
+
+	stats := serviceStats(g)
+	if metric == MetricNone || len(stats) == 0 {
+		return h
+	}
+
+	h.min, h.max = metric.value(stats[0]), metric.value(stats[0])
+	for _, s := range stats {
+		h.min, h.max = min(h.min, metric.value(s)), max(h.max, metric.value(s))
+	}
+
+	for _, s := range stats {
+		h.levels[s.Node] = h.level(metric.value(s))
+	}
+
+	return h
+}
+
+// level scales the value of the metric onto the levels of the heatmap
+func (h heatmap) level(value int) int {
+	if h.max == h.min {
+		return 1
+	}
+	return 1 + (value-h.min)*(heatLevels-1)/(h.max-h.min)
+}
+
+// heatDecorations fills a node with the color of the given level of the heatmap
+func heatDecorations(d Decorations, level int) Decorations {
+	d.palette = Palette{
+		ColorFill:   Color(fmt.Sprintf("/orrd%d/%d", heatLevels, level)),
+		ColorBorder: DarkGrey,
+		ColorFont:   DarkGrey,
+	}
+
+	if level > heatLevels/2 {
+		d.palette.ColorFont = White
+	}
+
+	return d
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetric(t *testing.T) {
+	for _, m := range []Metric{MetricNone, MetricFanIn, MetricFanOut} {
+		parsed, err := ParseMetric(m.String())
+		require.NoError(t, err)
+		assert.Equal(t, m, parsed)
+	}
+
+	_, err := ParseMetric("fan-out-in")
+	assert.ErrorContains(t, err, "available: none, fan-in, fan-out")
+}
+
+func TestPrintHeatmap(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "api", Label: "api", Category: CategoryService1},
+			{Name: "db", Label: "db", Category: CategoryDatabase},
+			{Name: "web", Label: "web", Category: CategoryService1},
+			{Name: "worker", Label: "worker", Category: CategoryService2},
+			{Name: "data", Label: "data", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "api", "db", compose.ConditionServiceHealthy),
+		dependsOn(0, "web", "db", compose.ConditionServiceHealthy),
+		dependsOn(0, "worker", "db", compose.ConditionServiceHealthy),
+		dependsOn(0, "web", "api", compose.ConditionServiceStarted),
+		mounts(0, "db", "data", "/data", false),
+	})
+
+	h := newHeatmap(g, MetricFanIn)
+	assert.Equal(t, 0, h.min)
+	assert.Equal(t, 3, h.max)
+	assert.Equal(t, map[NodeID]int{
+		{Name: "api"}:    3,
+		{Name: "db"}:     heatLevels,
+		{Name: "web"}:    1,
+		{Name: "worker"}: 1,
+	}, h.levels)
+
+	var b strings.Builder
+	require.NoError(t, PrintDOT(&b, g, DOTOptions{Heatmap: MetricFanIn}))
+
+	dot := b.String()
+	assert.Contains(t, dotNode(dot, "db"), `fillcolor = "/orrd9/9"`)
+	assert.Contains(t, dotNode(dot, "db"), `fontcolor = "white"`)
+	assert.Contains(t, dotNode(dot, "api"), `fillcolor = "/orrd9/3"`)
+	assert.Contains(t, dotNode(dot, "api"), `fontcolor = "/greys8/8"`)
+	assert.Contains(t, dotNode(dot, "legend_heatmap_0"), `label = "fan-in: 0"`)
+	assert.Contains(t, dotNode(dot, "legend_heatmap_1"), `label = "fan-in: 3"`)
+
+	// the volumes keep the colors of their category
+	assert.Contains(t, dotNode(dot, "data"), `fillcolor = "/greys8/7"`)
+
+	// without a heatmap, neither the nodes nor the legend change
+	b.Reset()
+	require.NoError(t, PrintDOT(&b, g, DOTOptions{}))
+	assert.NotContains(t, b.String(), "orrd9")
+}
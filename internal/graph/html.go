@@ -0,0 +1,36 @@
+package graph
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// PrintHTML will print the given graph as a self-contained interactive html page
+func PrintHTML(w io.Writer, g Graph) error {
+	svg, err := renderSVG(g, Annotations{})
+	if err != nil {
+		return err
+	}
+
+	err = viewerTemplate.Execute(w, struct {
+		Title string
+		SVG   template.HTML
+		Data  jsonGraph
+	}{
+		Title: "docker-compose-graph",
+		SVG:   template.HTML(svg),
+		Data:  graphData(g),
+	})
+	if err != nil {
+		return fmt.Errorf("could not write html page: %w", err)
+	}
+
+	return nil
+}
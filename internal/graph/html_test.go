@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintHTML(t *testing.T) {
+	var b strings.Builder
+
+	err := PrintHTML(&b, NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{
+			Name:     "my-database",
+			Label:    "my-database",
+			Category: CategoryDatabase,
+		}, {
+			Name:     "my-service",
+			Label:    "</script><script>alert(1)</script>",
+			Category: CategoryService1,
+			Labels:   map[string]string{"graph.node.category": "service1"},
+		}},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/tmp", true),
+	}))
+	require.NoError(t, err)
+
+	out := b.String()
+
+	// the svg image is embedded as-is
+	assert.Contains(t, out, `<g class="node" id="node_0_1" data-name="my-service" data-category="service1">`)
+	assert.Contains(t, out, `<g class="legend-node" id="node_1_0" data-name="service1" data-category="service1">`)
+	assert.Contains(t, out, `<path class="edge" data-from="node_0_1" data-to="node_0_0"`)
+
+	// the graph data is embedded as json
+	assert.Contains(t, out, `"dependencies":[{"on":"my-database","condition":"service_healthy","required":true,"restart":false}]`)
+	assert.Contains(t, out, `"volumes":[{"source":"my-volume","target":"/tmp","readOnly":true}]`)
+	assert.Contains(t, out, `"labels":{"graph.node.category":"service1"}`)
+
+	// labels cannot break out of the script or the svg
+	assert.NotContains(t, out, `</script><script>alert(1)`)
+
+	// no external resources
+	assert.NotContains(t, out, `src="http`)
+	assert.NotContains(t, out, `href="http`)
+}
@@ -0,0 +1,251 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+// Coupling is how a service is tied to a service it depends on (or shares a volume with), from
+// the weakest to the strongest
+type Coupling uint8
+
+const (
+	CouplingNone Coupling = iota
+
+	// CouplingOptional is a dependency which is not required to start the dependent
+	CouplingOptional
+
+	// CouplingSharedVolume is a volume mounted read-write by the failed service & mounted by the dependent
+	CouplingSharedVolume
+
+	CouplingStartOnly
+	CouplingCompletionGated
+	CouplingHealthGated
+
+	// CouplingRestart is a dependency restarting the dependent along with the failed service
+	CouplingRestart
+)
+
+var couplingStrings = []string{
+	"none",
+	"optional",
+	"shared-volume",
+	"start-only",
+	"completion-gated",
+	"health-gated",
+	"restart-propagating",
+}
+
+func (c Coupling) String() string {
+	if int(c) < len(couplingStrings) {
+		return couplingStrings[c]
+	}
+	return couplingStrings[CouplingNone]
+}
+
+// dependencyCoupling classifies the dependency edge
+func dependencyCoupling(e Edge) Coupling {
+	switch {
+	case !e.Required:
+		return CouplingOptional
+	case e.Restart:
+		return CouplingRestart
+	}
+
+	switch e.Condition {
+	case compose.ConditionServiceHealthy:
+		return CouplingHealthGated
+	case compose.ConditionServiceCompletedSuccessfully:
+		return CouplingCompletionGated
+	}
+
+	return CouplingStartOnly
+}
+
+// Impact is a service affected by the failure of other services
+type Impact struct {
+	Node NodeID
+
+	// Via is the service the node is coupled to on the way from a failed service (a failed service
+	// itself when Distance is 1), and Coupling how it is coupled to it
+	Via      NodeID
+	Coupling Coupling
+
+	// Volume is the volume shared with Via (for CouplingSharedVolume only)
+	Volume NodeID
+
+	// Effective is the weakest coupling on the way from a failed service, by which the impacts are ranked
+	Effective Coupling
+	Distance  int
+}
+
+// impactLink couples a service to the given one
+type impactLink struct {
+	from     NodeID
+	coupling Coupling
+	volume   NodeID
+}
+
+// impactLinks returns the services coupled to the given one: its dependents & the services mounting
+// the volumes it mounts read-write
+func impactLinks(g Graph, id NodeID) []impactLink {
+	var links []impactLink
+
+	for _, e := range g.Incoming(id) {
+		if e.Kind == EdgeKindDependency {
+			links = append(links, impactLink{from: e.From, coupling: dependencyCoupling(e)})
+		}
+	}
+
+	for _, e := range g.Outgoing(id) {
+		if e.Kind != EdgeKindVolumeMount || e.ReadOnly {
+			continue
+		}
+
+		for _, m := range g.Incoming(e.To) {
+			if m.Kind == EdgeKindVolumeMount && m.From != id {
+				links = append(links, impactLink{from: m.From, coupling: CouplingSharedVolume, volume: e.To})
+			}
+		}
+	}
+
+	return links
+}
+
+// ImpactOf returns the services affected by the failure of the given ones, walking the reverse
+// dependencies & the volumes mounted read-write; each service is reached along its strongest chain
+// of couplings (the shortest one among equals). The impacts are ranked by effective coupling, the
+// strongest first, then by distance
+func ImpactOf(g Graph, failed ...NodeID) []Impact {
+	impacts := make(map[NodeID]Impact)
+
+	// the failed services are the sources of the chains, as strong as can be
+	for _, id := range failed {
+		impacts[id] = Impact{Node: id, Effective: CouplingRestart}
+	}
+
+	queue := slices.Clone(failed)
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		from := impacts[id]
+
+		for _, link := range impactLinks(g, id) {
+			if slices.Contains(failed, link.from) {
+				continue
+			}
+
+			impact := Impact{
+				Node:      link.from,
+				Via:       id,
+				Coupling:  link.coupling,
+				Volume:    link.volume,
+				Effective: min(from.Effective, link.coupling),
+				Distance:  from.Distance + 1,
+			}
+
+			if current, ok := impacts[link.from]; ok && compareImpacts(current, impact) <= 0 {
+				continue
+			}
+
+			impacts[link.from] = impact
+			queue = append(queue, link.from)
+		}
+	}
+
+	for _, id := range failed {
+		delete(impacts, id)
+	}
+
+	ranked := slices.Collect(maps.Values(impacts))
+
+	slices.SortFunc(ranked, func(a, b Impact) int {
+		return cmp.Or(compareImpacts(a, b), compareNodeIDs(a.Node, b.Node))
+	})
+
+	return ranked
+}
+
+// compareImpacts orders the stronger impacts first, then the closer ones
+func compareImpacts(a, b Impact) int {
+	return cmp.Or(cmp.Compare(b.Effective, a.Effective), cmp.Compare(a.Distance, b.Distance))
+}
+
+// impactHighlights are the palettes of the failed services & of the services they affect (by
+// effective coupling) in the dot format; the other services are faded
+type impactHighlights struct {
+	failed  map[NodeID]bool
+	impacts map[NodeID]Impact
+}
+
+var (
+	failedPalette = Palette{ColorFill: Red, ColorBorder: DarkRed, ColorFont: White}
+	fadedPalette  = Palette{ColorFill: White, ColorBorder: LightGrey, ColorFont: LightGrey}
+)
+
+func newImpactHighlights(g Graph, failed []NodeID) impactHighlights {
+	h := impactHighlights{failed: make(map[NodeID]bool), impacts: make(map[NodeID]Impact)}
+
+	for _, id := range failed {
+		h.failed[id] = true
+	}
+
+	for _, impact := range ImpactOf(g, failed...) {
+		h.impacts[impact.Node] = impact
+	}
+
+	return h
+}
+
+// impactPalette shades the affected services from light (optional) to dark (restart-propagating)
+func impactPalette(c Coupling) Palette {
+	p := Palette{
+		ColorFill:   Color(fmt.Sprintf("/oranges9/%d", int(c)+2)),
+		ColorBorder: DarkOrange,
+		ColorFont:   DarkGrey,
+	}
+
+	if c >= CouplingHealthGated {
+		p.ColorFont = White
+	}
+
+	return p
+}
+
+// palette returns the palette of the service, if it is highlighted
+func (h impactHighlights) palette(id NodeID, node Node) (Palette, bool) {
+	if len(h.failed) == 0 || node.Category == CategoryVolume {
+		return Palette{}, false
+	}
+
+	if h.failed[id] {
+		return failedPalette, true
+	}
+
+	if impact, ok := h.impacts[id]; ok {
+		return impactPalette(impact.Effective), true
+	}
+
+	return fadedPalette, true
+}
+
+// couplings returns the effective couplings present among the impacts, the strongest first
+func (h impactHighlights) couplings() []Coupling {
+	var couplings []Coupling
+	for _, impact := range h.impacts {
+		if !slices.Contains(couplings, impact.Effective) {
+			couplings = append(couplings, impact.Effective)
+		}
+	}
+
+	slices.Sort(couplings)
+	slices.Reverse(couplings)
+
+	return couplings
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func impactGraph() Graph {
+	restart := dependsOn(0, "worker", "redis", compose.ConditionServiceStarted)
+	restart.Restart = true
+
+	optional := dependsOn(0, "cron", "api", compose.ConditionServiceStarted)
+	optional.Required = false
+
+	return NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "api", Label: "api", Category: CategoryService1},
+			{Name: "backup", Label: "backup", Category: CategoryTool},
+			{Name: "cron", Label: "cron", Category: CategoryTool},
+			{Name: "data", Label: "data", Category: CategoryVolume},
+			{Name: "job", Label: "job", Category: CategoryTool},
+			{Name: "reader", Label: "reader", Category: CategoryService2},
+			{Name: "redis", Label: "redis", Category: CategoryDatabase},
+			{Name: "tool", Label: "tool", Category: CategoryTool},
+			{Name: "web", Label: "web", Category: CategoryService1},
+			{Name: "worker", Label: "worker", Category: CategoryService2},
+		},
+	}}, []Edge{
+		dependsOn(0, "api", "redis", compose.ConditionServiceHealthy),
+		restart,
+		dependsOn(0, "web", "api", compose.ConditionServiceStarted),
+		dependsOn(0, "job", "web", compose.ConditionServiceCompletedSuccessfully),
+		optional,
+		mounts(0, "redis", "data", "/data", false),
+		mounts(0, "backup", "data", "/backup", false),
+		mounts(0, "reader", "data", "/data", true),
+	})
+}
+
+func TestImpactOf(t *testing.T) {
+	id := func(name string) NodeID { return NodeID{Group: 0, Name: name} }
+
+	assert.Equal(t, []Impact{
+		{Node: id("worker"), Via: id("redis"), Coupling: CouplingRestart, Effective: CouplingRestart, Distance: 1},
+		{Node: id("api"), Via: id("redis"), Coupling: CouplingHealthGated, Effective: CouplingHealthGated, Distance: 1},
+		{Node: id("web"), Via: id("api"), Coupling: CouplingStartOnly, Effective: CouplingStartOnly, Distance: 2},
+		{Node: id("job"), Via: id("web"), Coupling: CouplingCompletionGated, Effective: CouplingStartOnly, Distance: 3},
+		{Node: id("backup"), Via: id("redis"), Coupling: CouplingSharedVolume, Volume: id("data"), Effective: CouplingSharedVolume, Distance: 1},
+		{Node: id("reader"), Via: id("redis"), Coupling: CouplingSharedVolume, Volume: id("data"), Effective: CouplingSharedVolume, Distance: 1},
+		{Node: id("cron"), Via: id("api"), Coupling: CouplingOptional, Effective: CouplingOptional, Distance: 2},
+	}, ImpactOf(impactGraph(), id("redis")))
+
+	// a service mounting a volume read-only does not affect the others
+	assert.Empty(t, ImpactOf(impactGraph(), id("reader")))
+
+	// the failed services are not affected by each other, even along cycles
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceHealthy),
+		dependsOn(0, "b", "a", compose.ConditionServiceHealthy),
+		dependsOn(0, "c", "b", compose.ConditionServiceStarted),
+	})
+
+	assert.Equal(t, []Impact{
+		{Node: id("c"), Via: id("b"), Coupling: CouplingStartOnly, Effective: CouplingStartOnly, Distance: 1},
+	}, ImpactOf(g, id("a"), id("b")))
+}
+
+func TestPrintImpact(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, PrintDOT(&b, impactGraph(), DOTOptions{Failed: []NodeID{{Name: "redis"}}}))
+
+	dot := b.String()
+	assert.Contains(t, dotNode(dot, "redis"), `fillcolor = "/orrd8/7"`)
+	assert.Contains(t, dotNode(dot, "worker"), `fillcolor = "/oranges9/8"`)
+	assert.Contains(t, dotNode(dot, "cron"), `fillcolor = "/oranges9/3"`)
+	assert.Contains(t, dotNode(dot, "tool"), `fillcolor = "white"`)
+
+	// the volumes keep their colors
+	assert.Contains(t, dotNode(dot, "data"), `fillcolor = "/greys8/7"`)
+
+	assert.Contains(t, dotNode(dot, "legend_impact_0"), `label = "failed"`)
+	assert.Contains(t, dotNode(dot, "legend_impact_1"), `label = "restart-propagating"`)
+	assert.Contains(t, dotNode(dot, "legend_impact_5"), `label = "optional"`)
+	assert.Empty(t, dotNode(dot, "legend_impact_6"))
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonGraph is the graph data of the json format, also embedded into the html viewer
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+}
+
+type jsonNode struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Label        string            `json:"label"`
+	Group        string            `json:"group"`
+	Category     string            `json:"category"`
+	Dependencies []jsonDependency  `json:"dependencies"`
+	Volumes      []jsonVolume      `json:"volumes"`
+	Labels       map[string]string `json:"labels"`
+
+	// Status marks the nodes of a combined diff graph (see DiffGraph)
+	Status string `json:"status,omitempty"`
+
+	// volumes only: the services mounting the volume (see VolumeAccesses)
+	Writers   []string `json:"writers,omitempty"`
+	Readers   []string `json:"readers,omitempty"`
+	Contended bool     `json:"contended,omitempty"`
+}
+
+type jsonDependency struct {
+	On        string `json:"on"`
+	Condition string `json:"condition"`
+	Required  bool   `json:"required"`
+	Restart   bool   `json:"restart"`
+	Status    string `json:"status,omitempty"`
+}
+
+type jsonVolume struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly"`
+	Status   string `json:"status,omitempty"`
+}
+
+// PrintJSON will print the nodes of the given graph along with their dependencies & mounts as json;
+// the volumes list the services writing & reading them, and the nodes & edges of a combined diff
+// graph have the status of their changes
+func PrintJSON(w io.Writer, g Graph) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	if err := e.Encode(graphData(g)); err != nil {
+		return fmt.Errorf("could not write json: %w", err)
+	}
+
+	return nil
+}
+
+func graphData(g Graph) jsonGraph {
+	data := jsonGraph{Nodes: []jsonNode{}}
+
+	for gi, group := range g.Groups {
+		for ni, node := range group.Nodes {
+			n := jsonNode{
+				ID:           nodeID(layoutRef{group: gi, node: ni}),
+				Name:         node.Name,
+				Label:        node.Label,
+				Group:        group.Label,
+				Category:     node.Category.String(),
+				Dependencies: []jsonDependency{},
+				Volumes:      []jsonVolume{},
+				Labels:       node.Labels,
+				Status:       jsonStatus(node.Diff),
+			}
+
+			for _, e := range g.Outgoing(NodeID{Group: gi, Name: node.Name}) {
+				switch e.Kind {
+				case EdgeKindDependency:
+					n.Dependencies = append(n.Dependencies, jsonDependency{
+						On:        e.To.Name,
+						Condition: e.Condition.String(),
+						Required:  e.Required,
+						Restart:   e.Restart,
+						Status:    jsonStatus(e.Diff),
+					})
+
+				case EdgeKindVolumeMount:
+					n.Volumes = append(n.Volumes, jsonVolume{
+						Source:   e.To.Name,
+						Target:   e.Target,
+						ReadOnly: e.ReadOnly,
+						Status:   jsonStatus(e.Diff),
+					})
+				}
+			}
+
+			if node.Category == CategoryVolume {
+				a := volumeAccess(g, NodeID{Group: gi, Name: node.Name})
+
+				for _, id := range a.Writers {
+					n.Writers = append(n.Writers, id.Name)
+				}
+				for _, id := range a.Readers {
+					n.Readers = append(n.Readers, id.Name)
+				}
+				n.Contended = a.Contended()
+			}
+
+			data.Nodes = append(data.Nodes, n)
+		}
+	}
+
+	return data
+}
+
+// jsonStatus returns the diff status of a node or an edge ("added", "removed", "changed" or
+// "unchanged"), empty outside of a combined graph
+func jsonStatus(s DiffStatus) string {
+	if s == DiffNone {
+		return ""
+	}
+	return s.String()
+}
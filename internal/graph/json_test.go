@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintJSON(t *testing.T) {
+	var b strings.Builder
+
+	err := PrintJSON(&b, NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/data", false),
+	}))
+	require.NoError(t, err)
+
+	var data jsonGraph
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &data))
+
+	require.Len(t, data.Nodes, 3)
+	assert.Equal(t, jsonNode{
+		ID:           "node_0_1",
+		Name:         "my-service",
+		Label:        "my-service",
+		Group:        "docker-compose.yaml",
+		Category:     "service1",
+		Dependencies: []jsonDependency{{On: "my-database", Condition: "service_healthy", Required: true}},
+		Volumes:      []jsonVolume{{Source: "my-volume", Target: "/data"}},
+	}, data.Nodes[1])
+
+	// the volumes list their writers & readers
+	assert.Equal(t, []string{"my-service"}, data.Nodes[2].Writers)
+	assert.False(t, data.Nodes[2].Contended)
+
+	b.Reset()
+	require.NoError(t, PrintJSON(&b, contentionGraph()))
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &data))
+	assert.Equal(t, "data", data.Nodes[2].Name)
+	assert.Equal(t, []string{"backup", "writer"}, data.Nodes[2].Writers)
+	assert.Equal(t, []string{"reader"}, data.Nodes[2].Readers)
+	assert.True(t, data.Nodes[2].Contended)
+
+	// an empty graph has an empty list of nodes
+	b.Reset()
+	require.NoError(t, PrintJSON(&b, Graph{}))
+	assert.Equal(t, "{\n  \"nodes\": []\n}\n", b.String())
+}
+
+func TestPrintJSONDiff(t *testing.T) {
+	before := NewGraph([]NodeGroup{{
+		Label: "compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceStarted),
+	})
+
+	after := NewGraph([]NodeGroup{{
+		Label: "compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryTool},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-service", "my-volume", "/data", true),
+	})
+
+	var b strings.Builder
+	require.NoError(t, PrintJSON(&b, DiffGraph(before, after)))
+
+	var data jsonGraph
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &data))
+
+	require.Len(t, data.Nodes, 3)
+	assert.Equal(t, "unchanged", data.Nodes[0].Status)
+	assert.Equal(t, "changed", data.Nodes[1].Status)
+	assert.Equal(t, []jsonDependency{{On: "my-database", Condition: "service_started", Required: true, Status: "removed"}}, data.Nodes[1].Dependencies)
+	assert.Equal(t, []jsonVolume{{Source: "my-volume", Target: "/data", ReadOnly: true, Status: "added"}}, data.Nodes[1].Volumes)
+
+	// the status is left out of plain graphs
+	b.Reset()
+	require.NoError(t, PrintJSON(&b, after))
+	assert.NotContains(t, b.String(), `"status"`)
+}
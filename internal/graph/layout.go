@@ -0,0 +1,482 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// dimensions (in pixels) used when laying out the graph
+const (
+	layoutNodeHeight   = 40
+	layoutNodeMinWidth = 100
+	layoutNodePadding  = 30
+	layoutCharWidth    = 7
+	layoutNodeSep      = 30
+	layoutRankSep      = 60
+	layoutGroupPadding = 20
+	layoutGroupHeader  = 30
+	layoutGroupSep     = 40
+	layoutDummyWidth   = 10
+	layoutSampleWidth  = 40
+	layoutSampleHeight = 20
+
+	// number of the crossing reduction iterations
+	layoutSweeps = 8
+)
+
+// layout holds the coordinates of the nodes & groups for renderers which,
+// unlike dot, are not capable of arranging the graph on their own
+type layout struct {
+	groups  []layoutGroup
+	edges   []layoutEdge
+	samples []layoutSample
+	width   int
+	height  int
+
+	// legend is set if the last group is the legend
+	legend bool
+}
+
+type layoutGroup struct {
+	label  string
+	x, y   int
+	width  int
+	height int
+	nodes  []layoutNode
+}
+
+type layoutNode struct {
+	Node
+	x, y   int // relative to the containing group
+	width  int
+	height int
+	rank   int
+}
+
+type layoutEdge struct {
+	Edge
+	from  layoutRef
+	to    layoutRef
+	bends []point // absolute coordinates of the intermediate points, if any
+}
+
+type point struct {
+	x, y int
+}
+
+// layoutSample is a legend edge sample drawn as a short horizontal line followed by its label
+type layoutSample struct {
+	legendEdge
+	x, y int // absolute coordinates of the start of the line
+}
+
+// layoutRef references a node by its group & node index
+type layoutRef struct {
+	group int
+	node  int
+}
+
+// computeLayout arranges each group into layers (dependents above their
+// dependencies) and places the groups next to each other from left to right
+func computeLayout(g Graph) layout {
+	l := newLayout(g)
+
+	x := 0
+
+	for gi := range l.groups {
+		l.arrange(gi)
+
+		g := &l.groups[gi]
+		g.x = x
+
+		// bends are computed relative to the group
+		for i := range l.edges {
+			if l.edges[i].from.group == gi {
+				for b := range l.edges[i].bends {
+					l.edges[i].bends[b].x += g.x
+					l.edges[i].bends[b].y += g.y
+				}
+			}
+		}
+
+		x += g.width + layoutGroupSep
+
+		l.width = g.x + g.width
+		l.height = max(l.height, g.height)
+	}
+
+	return l
+}
+
+// computeLegendLayout arranges the graph along with its legend according to its legend placement
+func computeLegendLayout(g Graph) layout {
+	withLegend, samples := g.withLegend()
+
+	l := computeLayout(withLegend)
+
+	if g.Legend != LegendHidden {
+		l.legend = true
+		l.appendSamples(samples)
+	}
+
+	return l
+}
+
+// isLegend reports whether the group at the given index is the legend
+func (l *layout) isLegend(group int) bool {
+	return l.legend && group == len(l.groups)-1
+}
+
+// appendSamples places the legend edge samples in rows below the nodes of the last group (the legend)
+func (l *layout) appendSamples(samples []legendEdge) {
+	if len(samples) == 0 || len(l.groups) == 0 {
+		return
+	}
+
+	g := &l.groups[len(l.groups)-1]
+
+	// the samples are placed within the bottom padding of the group, which is then extended
+	top := g.y + g.height - layoutGroupPadding/2
+
+	for i, sample := range samples {
+		l.samples = append(l.samples, layoutSample{
+			legendEdge: sample,
+			x:          g.x + layoutGroupPadding,
+			y:          top + i*layoutSampleHeight + layoutSampleHeight/2,
+		})
+
+		g.width = max(g.width, 2*layoutGroupPadding+layoutSampleWidth+textWidth(sample.label, 0))
+	}
+
+	g.height += len(samples) * layoutSampleHeight
+
+	l.width = max(l.width, g.x+g.width)
+	l.height = max(l.height, g.y+g.height)
+}
+
+// newLayout locates the endpoints of the edges without assigning any coordinates;
+// edges to nodes missing from the graph are omitted
+func newLayout(g Graph) layout {
+	var l layout
+
+	for _, group := range g.Groups {
+		lg := layoutGroup{label: group.Label}
+
+		for _, node := range group.Nodes {
+			lg.nodes = append(lg.nodes, layoutNode{
+				Node:   node,
+				width:  textWidth(node.Label, layoutNodeMinWidth),
+				height: layoutNodeHeight,
+			})
+		}
+
+		l.groups = append(l.groups, lg)
+	}
+
+	for _, e := range g.Edges {
+		from, ok := g.nodes[e.From]
+		if !ok {
+			continue
+		}
+
+		to, ok := g.nodes[e.To]
+		if !ok {
+			continue
+		}
+
+		l.edges = append(l.edges, layoutEdge{
+			Edge: e,
+			from: layoutRef{group: from.group, node: from.node},
+			to:   layoutRef{group: to.group, node: to.node},
+		})
+	}
+
+	return l
+}
+
+// nodeID returns a unique identifier for the referenced node (names may repeat across groups)
+func nodeID(ref layoutRef) string {
+	return fmt.Sprintf("node_%d_%d", ref.group, ref.node)
+}
+
+// node returns the node referenced by the given ref
+func (l *layout) node(ref layoutRef) *layoutNode {
+	return &l.groups[ref.group].nodes[ref.node]
+}
+
+// absolute returns the absolute coordinates of the top-left corner of the referenced node
+func (l *layout) absolute(ref layoutRef) (int, int) {
+	g, n := l.groups[ref.group], l.node(ref)
+	return g.x + n.x, g.y + n.y
+}
+
+// arrange assigns ranks & coordinates to the nodes within the given group
+// following the usual layered (Sugiyama) approach: cycle removal, layering,
+// crossing reduction & coordinate assignment
+func (l *layout) arrange(group int) {
+	g := &l.groups[group]
+
+	// edges within the group, excluding self-references
+	var internal []int
+
+	successors := make([][]int, len(g.nodes))
+
+	for i, e := range l.edges {
+		if e.from.group == group && e.to.group == group && e.from.node != e.to.node {
+			internal = append(internal, i)
+			successors[e.from.node] = append(successors[e.from.node], e.to.node)
+		}
+	}
+
+	// a depth-first search yields a topological order, ignoring the back edges of any cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(g.nodes))
+	order := make([]int, 0, len(g.nodes))
+
+	var visit func(int)
+	visit = func(n int) {
+		state[n] = visiting
+		for _, s := range successors[n] {
+			if state[s] == unvisited {
+				visit(s)
+			}
+		}
+		state[n] = visited
+		order = append(order, n)
+	}
+
+	for n := range g.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	slices.Reverse(order)
+
+	position := make([]int, len(g.nodes))
+	for i, n := range order {
+		position[n] = i
+	}
+
+	// longest-path layering: each node is placed one rank below the lowest of its dependents
+	for _, n := range order {
+		for _, s := range successors[n] {
+			if position[s] > position[n] {
+				g.nodes[s].rank = max(g.nodes[s].rank, g.nodes[n].rank+1)
+			}
+		}
+	}
+
+	var ranks [][]layoutSlot
+
+	addSlot := func(rank int, slot layoutSlot) int {
+		for len(ranks) <= rank {
+			ranks = append(ranks, nil)
+		}
+		ranks[rank] = append(ranks[rank], slot)
+		return slot.id
+	}
+
+	for n, node := range g.nodes {
+		addSlot(node.rank, layoutSlot{id: n, node: n, width: node.width})
+	}
+
+	// edges spanning multiple ranks are routed through dummy slots on the intermediate ranks;
+	// neighbors links the slots on adjacent ranks (by slot id) for the crossing reduction
+	var (
+		neighbors = make(map[int][]int)
+		nextID    = len(g.nodes)
+	)
+
+	link := func(upper, lower int) {
+		neighbors[upper] = append(neighbors[upper], lower)
+		neighbors[lower] = append(neighbors[lower], upper)
+	}
+
+	for _, i := range internal {
+		from, to := l.edges[i].from.node, l.edges[i].to.node
+
+		if g.nodes[to].rank <= g.nodes[from].rank {
+			continue // back edge
+		}
+
+		previous := from
+		for r := g.nodes[from].rank + 1; r < g.nodes[to].rank; r++ {
+			id := addSlot(r, layoutSlot{id: nextID, node: -1, edge: i, width: layoutDummyWidth})
+			nextID++
+			link(previous, id)
+			previous = id
+		}
+		link(previous, to)
+	}
+
+	reduceCrossings(ranks, neighbors)
+
+	// the widest rank determines the width of the group
+	widths := make([]int, len(ranks))
+
+	for r, slots := range ranks {
+		for i, slot := range slots {
+			if i != 0 {
+				widths[r] += layoutNodeSep
+			}
+			widths[r] += slot.width
+		}
+	}
+
+	inner := max(slices.Max(append(widths, 0)), textWidth(g.label, 0))
+
+	for r, slots := range ranks {
+		// center each rank horizontally
+		x := layoutGroupPadding + (inner-widths[r])/2
+		y := layoutGroupHeader + layoutGroupPadding + r*(layoutNodeHeight+layoutRankSep)
+
+		for _, slot := range slots {
+			if slot.node != -1 {
+				g.nodes[slot.node].x = x
+				g.nodes[slot.node].y = y
+			} else {
+				l.edges[slot.edge].bends = append(l.edges[slot.edge].bends, point{
+					x: x + slot.width/2,
+					y: y + layoutNodeHeight/2,
+				})
+			}
+			x += slot.width + layoutNodeSep
+		}
+	}
+
+	g.width = inner + 2*layoutGroupPadding
+	g.height = layoutGroupHeader + 2*layoutGroupPadding
+
+	if len(ranks) > 0 {
+		g.height += len(ranks)*layoutNodeHeight + (len(ranks)-1)*layoutRankSep
+	}
+}
+
+// layoutSlot is a position within a rank occupied by either a node or an edge passing through
+type layoutSlot struct {
+	id    int
+	node  int // -1 for edges passing through
+	edge  int
+	width int
+}
+
+// reduceCrossings reorders the slots within each rank using the barycenter
+// heuristic, keeping the ordering with the fewest edge crossings
+func reduceCrossings(ranks [][]layoutSlot, neighbors map[int][]int) {
+	positions := make(map[int]int)
+
+	index := func() {
+		for _, slots := range ranks {
+			for i, slot := range slots {
+				positions[slot.id] = i
+			}
+		}
+	}
+
+	index()
+
+	best := cloneRanks(ranks)
+	bestCrossings := countCrossings(ranks, neighbors, positions)
+
+	// sorts the given rank by the average position of the neighbors in the adjacent rank
+	sortRank := func(r, adjacent int) {
+		barycenters := make(map[int]float64)
+
+		for i, slot := range ranks[r] {
+			var sum, count int
+			for _, n := range neighbors[slot.id] {
+				if p, ok := positions[n]; ok && rankOf(ranks, adjacent, n) {
+					sum += p
+					count++
+				}
+			}
+			if count == 0 {
+				barycenters[slot.id] = float64(i)
+			} else {
+				barycenters[slot.id] = float64(sum) / float64(count)
+			}
+		}
+
+		slices.SortStableFunc(ranks[r], func(a, b layoutSlot) int {
+			return cmp.Compare(barycenters[a.id], barycenters[b.id])
+		})
+
+		for i, slot := range ranks[r] {
+			positions[slot.id] = i
+		}
+	}
+
+	for range layoutSweeps {
+		for r := 1; r < len(ranks); r++ {
+			sortRank(r, r-1)
+		}
+		for r := len(ranks) - 2; r >= 0; r-- {
+			sortRank(r, r+1)
+		}
+
+		if c := countCrossings(ranks, neighbors, positions); c < bestCrossings {
+			best, bestCrossings = cloneRanks(ranks), c
+		}
+	}
+
+	for r := range ranks {
+		copy(ranks[r], best[r])
+	}
+}
+
+// countCrossings counts the crossings between the links of all adjacent ranks
+func countCrossings(ranks [][]layoutSlot, neighbors map[int][]int, positions map[int]int) int {
+	var crossings int
+
+	for r := 0; r+1 < len(ranks); r++ {
+		type segment struct{ upper, lower int }
+
+		var segments []segment
+
+		for _, slot := range ranks[r] {
+			for _, n := range neighbors[slot.id] {
+				if rankOf(ranks, r+1, n) {
+					segments = append(segments, segment{upper: positions[slot.id], lower: positions[n]})
+				}
+			}
+		}
+
+		for i := range segments {
+			for j := i + 1; j < len(segments); j++ {
+				a, b := segments[i], segments[j]
+				if (a.upper < b.upper && a.lower > b.lower) || (a.upper > b.upper && a.lower < b.lower) {
+					crossings++
+				}
+			}
+		}
+	}
+
+	return crossings
+}
+
+// rankOf reports whether the slot with the given id is within the given rank
+func rankOf(ranks [][]layoutSlot, rank int, id int) bool {
+	return slices.ContainsFunc(ranks[rank], func(s layoutSlot) bool {
+		return s.id == id
+	})
+}
+
+func cloneRanks(ranks [][]layoutSlot) [][]layoutSlot {
+	cloned := make([][]layoutSlot, len(ranks))
+	for r := range ranks {
+		cloned[r] = slices.Clone(ranks[r])
+	}
+	return cloned
+}
+
+// textWidth estimates the width of a box containing the given text
+func textWidth(text string, minimum int) int {
+	return max(minimum, len([]rune(text))*layoutCharWidth+layoutNodePadding)
+}
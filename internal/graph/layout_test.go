@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeLayout(t *testing.T) {
+	groups := []NodeGroup{{
+		Label: "docker-compose-1.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}, {
+		Label: "docker-compose-2.yaml",
+		Nodes: []Node{
+			{Name: "my-tool", Label: "my-tool", Category: CategoryTool},
+		},
+	}}
+
+	crossGroup := Edge{
+		Kind:      EdgeKindDependency,
+		From:      NodeID{Group: 1, Name: "my-tool"},
+		To:        NodeID{Group: 0, Name: "my-service"},
+		Condition: compose.ConditionServiceStarted,
+		Required:  true,
+	}
+
+	l := computeLayout(NewGraph(groups, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/tmp", true),
+		crossGroup,
+		dependsOn(0, "my-service", "missing", compose.ConditionServiceStarted),
+	}))
+
+	require.Len(t, l.groups, 2)
+	require.Len(t, l.edges, 4, "edges to missing nodes are omitted")
+
+	// dependents are ranked above their dependencies
+	assert.Equal(t, 1, l.groups[0].nodes[0].rank)
+	assert.Equal(t, 0, l.groups[0].nodes[1].rank)
+	assert.Equal(t, 2, l.groups[0].nodes[2].rank)
+	assert.Less(t, l.groups[0].nodes[1].y, l.groups[0].nodes[0].y)
+	assert.Less(t, l.groups[0].nodes[0].y, l.groups[0].nodes[2].y)
+
+	// edges across groups are located
+	assert.Equal(t, layoutEdge{
+		Edge: crossGroup,
+		from: layoutRef{group: 1, node: 0},
+		to:   layoutRef{group: 0, node: 1},
+	}, l.edges[3])
+
+	// groups are placed from left to right without overlapping
+	assert.Equal(t, 0, l.groups[0].x)
+	assert.Equal(t, l.groups[0].width+layoutGroupSep, l.groups[1].x)
+	assert.Equal(t, l.groups[1].x+l.groups[1].width, l.width)
+}
+
+func TestComputeLayoutCycle(t *testing.T) {
+	l := computeLayout(NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{Name: "a"}, {Name: "b"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "a", compose.ConditionServiceStarted),
+	}))
+
+	assert.Equal(t, 0, l.groups[0].nodes[0].rank)
+	assert.Equal(t, 1, l.groups[0].nodes[1].rank)
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Equal(t, 100, textWidth("short", 100))
+	assert.Equal(t, 30, textWidth("", 0))
+	assert.Equal(t, 7*20+30, textWidth("a-rather-long-name-1", 100))
+}
+
+func TestReduceCrossings(t *testing.T) {
+	// a -> d, b -> c: crossing in the initial order
+	ranks := [][]layoutSlot{
+		{{id: 0, node: 0}, {id: 1, node: 1}},
+		{{id: 2, node: 2}, {id: 3, node: 3}},
+	}
+	neighbors := map[int][]int{
+		0: {3},
+		1: {2},
+		2: {1},
+		3: {0},
+	}
+
+	assert.Equal(t, 1, countCrossings(ranks, neighbors, map[int]int{0: 0, 1: 1, 2: 0, 3: 1}))
+
+	reduceCrossings(ranks, neighbors)
+
+	assert.Equal(t, 0, countCrossings(ranks, neighbors, map[int]int{
+		ranks[0][0].id: 0,
+		ranks[0][1].id: 1,
+		ranks[1][0].id: 0,
+		ranks[1][1].id: 1,
+	}))
+}
+
+func TestComputeLayoutBends(t *testing.T) {
+	// a -> b -> c and a -> c: the long edge is routed through a bend on the middle rank
+	l := computeLayout(NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+		dependsOn(0, "a", "c", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "c", compose.ConditionServiceStarted),
+	}))
+
+	require.Len(t, l.edges, 3)
+	assert.Empty(t, l.edges[0].bends)
+	require.Len(t, l.edges[1].bends, 1)
+	assert.Empty(t, l.edges[2].bends)
+
+	b := l.groups[0].nodes[1]
+	assert.Equal(t, b.y+layoutNodeHeight/2, l.edges[1].bends[0].y)
+}
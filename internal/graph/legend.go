@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+// Legend controls where the renderers place the legend
+type Legend uint8
+
+const (
+	// LegendInline appends the legend to the graph as its last group
+	LegendInline Legend = iota
+
+	// LegendHidden omits the legend
+	LegendHidden
+
+	// LegendOnly renders the legend alone (e.g. into a separate output) in place of the graph
+	LegendOnly
+)
+
+var legendStrings = []string{
+	"inline",
+	"none",
+	"only",
+}
+
+func (l Legend) String() string {
+	if int(l) < len(legendStrings) {
+		return legendStrings[l]
+	}
+	return legendStrings[LegendInline]
+}
+
+// ParseLegend returns the legend placement with the given name ("inline", "none" or "only")
+func ParseLegend(name string) (Legend, error) {
+	for i, s := range legendStrings {
+		if s == name {
+			return Legend(i), nil
+		}
+	}
+	return LegendInline, fmt.Errorf("unknown legend '%s' (available: %s)", name, strings.Join(legendStrings, ", "))
+}
+
+// legendEdge is a sample edge explaining the decorations of an edge variant in the legend
+type legendEdge struct {
+	label       string
+	decorations EdgeDecorations
+}
+
+// legendConditions are the dependency conditions in the order of their legend samples
+var legendConditions = []compose.Condition{
+	compose.ConditionServiceStarted,
+	compose.ConditionServiceHealthy,
+	compose.ConditionServiceCompletedSuccessfully,
+}
+
+// legendSamples returns the edge samples of the legend of the graph: the edge variants followed by
+// the statuses of a combined graph
+func legendSamples(g Graph) []legendEdge {
+	return append(legendEdges(g.Edges), diffSamples(g)...)
+}
+
+// legendEdges returns an ordered list of samples of the edge variants present among the edges:
+// the dependency conditions, the read-write & read-only mounts and the optional & restarting dependencies
+func legendEdges(edges []Edge) []legendEdge {
+	var (
+		conditions          = make(map[compose.Condition]bool)
+		readWrite, readOnly bool
+		optional, restart   bool
+	)
+
+	for _, e := range edges {
+		switch e.Kind {
+		case EdgeKindDependency:
+			conditions[e.Condition] = true
+			optional = optional || !e.Required
+			restart = restart || e.Restart
+
+		case EdgeKindVolumeMount:
+			readWrite = readWrite || !e.ReadOnly
+			readOnly = readOnly || e.ReadOnly
+		}
+	}
+
+	var samples []legendEdge
+
+	for _, condition := range legendConditions {
+		if conditions[condition] {
+			d, _ := dependencyDecorations(condition)
+			samples = append(samples, legendEdge{label: condition.String(), decorations: d})
+		}
+	}
+
+	if readWrite {
+		samples = append(samples, legendEdge{label: "read-write mount", decorations: mountDecorations(false)})
+	}
+
+	if readOnly {
+		samples = append(samples, legendEdge{label: "read-only mount", decorations: mountDecorations(true)})
+	}
+
+	started, _ := dependencyDecorations(compose.ConditionServiceStarted)
+
+	if optional {
+		samples = append(samples, legendEdge{label: "optional", decorations: optionalDecorations(started)})
+	}
+
+	if restart {
+		samples = append(samples, legendEdge{label: "restart", decorations: restartDecorations(started)})
+	}
+
+	return samples
+}
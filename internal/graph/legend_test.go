@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegend(t *testing.T) {
+	for _, legend := range []Legend{LegendInline, LegendHidden, LegendOnly} {
+		parsed, err := ParseLegend(legend.String())
+		require.NoError(t, err)
+		assert.Equal(t, legend, parsed)
+	}
+
+	_, err := ParseLegend("bottom")
+	assert.EqualError(t, err, "unknown legend 'bottom' (available: inline, none, only)")
+}
+
+func TestLegendEdges(t *testing.T) {
+	optional := dependsOn(0, "a", "b", compose.ConditionServiceHealthy)
+	optional.Required = false
+
+	restart := dependsOn(0, "a", "c", compose.ConditionServiceStarted)
+	restart.Restart = true
+
+	assert.Empty(t, legendEdges(nil))
+
+	// ordered by condition, then mounts, regardless of the order of the edges
+	assert.Equal(t, []legendEdge{
+		{label: "service_started", decorations: EdgeDecorations{styles: []Style{Dashed}}},
+		{label: "service_completed_successfully", decorations: EdgeDecorations{styles: []Style{Bold}}},
+		{label: "read-only mount", decorations: EdgeDecorations{styles: []Style{Dashed}}},
+	}, legendEdges([]Edge{
+		mounts(0, "a", "my-volume", "/data", true),
+		dependsOn(0, "a", "b", compose.ConditionServiceCompletedSuccessfully),
+		dependsOn(0, "a", "c", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "c", compose.ConditionServiceStarted),
+	}))
+
+	assert.Equal(t, []legendEdge{
+		{label: "service_started", decorations: EdgeDecorations{styles: []Style{Dashed}}},
+		{label: "service_healthy", decorations: EdgeDecorations{styles: []Style{Bold}, arrowhead: ArrowDiamond}},
+		{label: "read-write mount", decorations: EdgeDecorations{styles: []Style{Bold, Dashed}}},
+		{label: "optional", decorations: EdgeDecorations{styles: []Style{Dotted}, color: LightGrey}},
+		{label: "restart", decorations: EdgeDecorations{styles: []Style{Dashed}, arrowtail: ArrowDot}},
+	}, legendEdges([]Edge{
+		restart,
+		optional,
+		mounts(0, "a", "my-volume", "/data", false),
+	}))
+}
+
+func TestPrintLegendEdges(t *testing.T) {
+	restart := dependsOn(0, "a", "b", compose.ConditionServiceStarted)
+	restart.Restart = true
+
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "a", Label: "a", Category: CategoryService1},
+			{Name: "b", Label: "b", Category: CategoryService1},
+		},
+	}}, []Edge{restart})
+
+	var b strings.Builder
+	require.NoError(t, Print(&b, g))
+
+	assert.Contains(t, b.String(), `legend_edge_0                        [shape = "plaintext"  fontsize = "8pt"  label = "service_started"];`)
+	assert.Contains(t, b.String(), `legend_edge_1                        [shape = "plaintext"  fontsize = "8pt"  label = "restart"];`)
+	assert.Contains(t, b.String(), `[style="dashed" dir="both" arrowtail="dot"];`)
+
+	b.Reset()
+	require.NoError(t, PrintSVG(&b, g, SVGOptions{}))
+
+	assert.Contains(t, b.String(), `<g class="legend-edge">`)
+	assert.Contains(t, b.String(), `marker-start="url(#arrow-dot)"`)
+}
+
+func TestPrintLegendPlacement(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "a", Label: "a", Category: CategoryService1},
+			{Name: "b", Label: "b", Category: CategoryDatabase},
+		},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceHealthy),
+	})
+
+	for _, tc := range []struct {
+		placement     Legend
+		graph, legend bool
+	}{
+		{placement: LegendInline, graph: true, legend: true},
+		{placement: LegendHidden, graph: true, legend: false},
+		{placement: LegendOnly, graph: false, legend: true},
+	} {
+		t.Run(tc.placement.String(), func(t *testing.T) {
+			g.Legend = tc.placement
+
+			var dot, svg, drawio strings.Builder
+			require.NoError(t, Print(&dot, g))
+			require.NoError(t, PrintSVG(&svg, g, SVGOptions{}))
+			require.NoError(t, PrintDrawIO(&drawio, g))
+
+			assert.Equal(t, tc.graph, strings.Contains(dot.String(), `label = "docker-compose.yaml"`))
+			assert.Equal(t, tc.graph, strings.Contains(dot.String(), `a                                      -> b`))
+			assert.Equal(t, tc.legend, strings.Contains(dot.String(), `label = "Legend"`))
+
+			assert.Equal(t, tc.graph, strings.Contains(svg.String(), `class="node"`))
+			assert.Equal(t, tc.legend, strings.Contains(svg.String(), `class="legend-node"`))
+			assert.Equal(t, tc.legend, strings.Contains(svg.String(), `class="legend-edge"`))
+
+			assert.Equal(t, tc.graph, strings.Contains(drawio.String(), `value="docker-compose.yaml"`))
+			assert.Equal(t, tc.legend, strings.Contains(drawio.String(), `value="Legend"`))
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+// graphLintIgnore is the label of a service listing the ids of the lint rules to suppress (comma-separated)
+const graphLintIgnore = "graph.lint.ignore"
+
+type Severity uint8
+
+const (
+	SeverityOff Severity = iota
+	SeverityNote
+	SeverityWarning
+	SeverityError
+)
+
+var severityStrings = []string{
+	"off",
+	"note",
+	"warning",
+	"error",
+}
+
+func (s Severity) String() string {
+	if int(s) < len(severityStrings) {
+		return severityStrings[s]
+	}
+	return severityStrings[SeverityOff]
+}
+
+// ParseSeverity returns the severity with the given name ("off", "note", "warning" or "error")
+func ParseSeverity(s string) (Severity, bool) {
+	i := slices.Index(severityStrings, strings.ToLower(s))
+	if i == -1 {
+		return SeverityOff, false
+	}
+	return Severity(i), true
+}
+
+// LintRule is an opinionated check of the services & volumes, beyond the integrity checks of Validate
+type LintRule struct {
+	ID          string
+	Description string
+
+	// Severity is the default severity of the findings of the rule
+	Severity Severity
+
+	check func(g Graph) []Finding
+}
+
+// Finding is an issue with a node reported by a lint rule
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Node     NodeID
+	Message  string
+
+	// Path & Line locate the definition of the service (or the file declaring the volume), if known
+	Path string
+	Line int
+}
+
+var lintRules = []LintRule{{
+	ID:          "database-dependency-not-healthy",
+	Description: "a service depends on a database without waiting for it to be healthy",
+	Severity:    SeverityWarning,
+	check:       lintDatabaseDependencies,
+}, {
+	ID:          "shared-writable-volume",
+	Description: "a volume is mounted read-write by more than one service",
+	Severity:    SeverityWarning,
+	check:       lintSharedVolumes,
+}, {
+	ID:          "missing-category-label",
+	Description: "a service has no '" + graphNodeCategory + "' label nor a category in the config & is categorized by its name",
+	Severity:    SeverityNote,
+	check:       lintCategoryLabels,
+}, {
+	ID:          "latest-image-tag",
+	Description: "a service uses the 'latest' tag of its image, explicitly or implicitly",
+	Severity:    SeverityWarning,
+	check:       lintImageTags,
+}, {
+	ID:          "docker-socket-mount",
+	Description: "a service bind mounts the docker socket, granting it control of the host",
+	Severity:    SeverityError,
+	check:       lintDockerSocket,
+}, {
+	ID:          "unused-volume",
+	Description: "a volume is declared but not mounted by any service (volumes have no labels to suppress it with, turn the rule off instead)",
+	Severity:    SeverityWarning,
+	check:       lintUnusedVolumes,
+}}
+
+// LintRules returns the lint rules along with their default severities
+func LintRules() []LintRule {
+	return slices.Clone(lintRules)
+}
+
+// Lint checks the graph against the lint rules, with the given severities (by rule id) overriding
+// the default ones; the rules listed in the 'graph.lint.ignore' label of a service are not
+// reported for it (the findings of volumes, which have no labels, cannot be suppressed). The
+// findings are ordered by node, then by rule
+func Lint(g Graph, severities map[string]Severity) []Finding {
+	var findings []Finding
+
+	for _, rule := range lintRules {
+		severity, ok := severities[rule.ID]
+		if !ok {
+			severity = rule.Severity
+		}
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, f := range rule.check(g) {
+			node, _ := g.Node(f.Node)
+			if slices.Contains(splitLabel(node.Labels[graphLintIgnore]), rule.ID) {
+				continue
+			}
+
+			f.Rule, f.Severity, f.Path, f.Line = rule.ID, severity, node.Path, node.Line
+			findings = append(findings, f)
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Node.Group, b.Node.Group),
+			cmp.Compare(a.Node.Name, b.Node.Name),
+			cmp.Compare(a.Rule, b.Rule),
+		)
+	})
+
+	return findings
+}
+
+// splitLabel splits the comma-separated label value
+func splitLabel(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// eachNode calls f with each node of the graph
+func (g Graph) eachNode(f func(id NodeID, node Node)) {
+	for gi, group := range g.Groups {
+		for _, node := range group.Nodes {
+			f(NodeID{Group: gi, Name: node.Name}, node)
+		}
+	}
+}
+
+func lintDatabaseDependencies(g Graph) []Finding {
+	var findings []Finding
+
+	for _, e := range g.Edges {
+		if e.Kind != EdgeKindDependency || e.Condition == compose.ConditionServiceHealthy {
+			continue
+		}
+
+		if to, ok := g.Node(e.To); ok && to.Category == CategoryDatabase {
+			findings = append(findings, Finding{
+				Node:    e.From,
+				Message: fmt.Sprintf("depends on database '%s' with condition %s rather than %s", e.To.Name, e.Condition, compose.ConditionServiceHealthy),
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintSharedVolumes(g Graph) []Finding {
+	var findings []Finding
+
+	for _, a := range VolumeAccesses(g) {
+		if !a.Contended() {
+			continue
+		}
+
+		for _, writer := range a.Writers {
+			var others []string
+			for _, other := range a.Writers {
+				if other != writer {
+					others = append(others, "'"+other.Name+"'")
+				}
+			}
+
+			findings = append(findings, Finding{
+				Node:    writer,
+				Message: fmt.Sprintf("mounts volume '%s' read-write along with %s", a.Volume.Name, strings.Join(others, ", ")),
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintCategoryLabels(g Graph) []Finding {
+	var findings []Finding
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category == CategoryVolume || node.CategoryConfigured {
+			return
+		}
+
+		if _, ok := node.Labels[graphNodeCategory]; !ok {
+			findings = append(findings, Finding{
+				Node:    id,
+				Message: fmt.Sprintf("has no '%s' label (categorized as %s)", graphNodeCategory, node.Category),
+			})
+		}
+	})
+
+	return findings
+}
+
+func lintImageTags(g Graph) []Finding {
+	var findings []Finding
+
+	g.eachNode(func(id NodeID, node Node) {
+		// an image pinned by digest is immutable, whatever its tag
+		if node.Image == "" || strings.Contains(node.Image, "@") {
+			return
+		}
+
+		// the registry may have a port, the tag follows the last path component
+		_, tag, ok := strings.Cut(path.Base(node.Image), ":")
+
+		switch {
+		case !ok:
+			findings = append(findings, Finding{
+				Node:    id,
+				Message: fmt.Sprintf("image '%s' has no tag ('latest' is implied)", node.Image),
+			})
+		case tag == "latest":
+			findings = append(findings, Finding{
+				Node:    id,
+				Message: fmt.Sprintf("uses the 'latest' tag of image '%s'", node.Image),
+			})
+		}
+	})
+
+	return findings
+}
+
+// dockerSockets are the paths of the docker socket on the host
+var dockerSockets = []string{"/var/run/docker.sock", "/run/docker.sock"}
+
+func lintDockerSocket(g Graph) []Finding {
+	var findings []Finding
+
+	g.eachNode(func(id NodeID, node Node) {
+		for _, source := range node.Binds {
+			if slices.Contains(dockerSockets, path.Clean(source)) {
+				findings = append(findings, Finding{
+					Node:    id,
+					Message: fmt.Sprintf("bind mounts the docker socket '%s'", source),
+				})
+			}
+		}
+	})
+
+	return findings
+}
+
+func lintUnusedVolumes(g Graph) []Finding {
+	var findings []Finding
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category == CategoryVolume && len(g.Incoming(id)) == 0 {
+			findings = append(findings, Finding{
+				Node:    id,
+				Message: "is declared but not mounted by any service",
+			})
+		}
+	})
+
+	return findings
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	categorized := map[string]string{graphNodeCategory: "service1"}
+
+	g := NewGraph([]NodeGroup{{
+		Label: "compose.yaml",
+		Nodes: []Node{
+			{Name: "agent", Category: CategoryService1, Labels: categorized, Image: "portainer/agent:2.19", Binds: []string{"/var/run/docker.sock"}, Path: "compose.yaml", Line: 2},
+			{Name: "api", Category: CategoryService1, Labels: categorized, Image: "registry:5000/api", Path: "compose.yaml", Line: 5},
+			{Name: "db", Category: CategoryDatabase, Labels: map[string]string{graphNodeCategory: "database"}, Image: "postgres@sha256:0123", Path: "compose.yaml", Line: 9},
+			{Name: "web", Category: CategoryService1, Image: "nginx:latest", Path: "compose.yaml", Line: 12},
+			{Name: "proxy", Category: CategoryTool, CategoryConfigured: true, Image: "traefik:v3.1"}, // categorized by the config
+			{Name: "worker", Category: CategoryService1, Labels: map[string]string{graphNodeCategory: "service1", graphLintIgnore: "latest-image-tag, shared-writable-volume"}, Image: "worker:latest"},
+			{Name: "data", Category: CategoryVolume, Path: "compose.yaml"},
+			{Name: "unused", Category: CategoryVolume, Path: "compose.yaml"},
+		},
+	}}, []Edge{
+		dependsOn(0, "api", "db", compose.ConditionServiceStarted),
+		dependsOn(0, "web", "api", compose.ConditionServiceStarted),
+		dependsOn(0, "worker", "db", compose.ConditionServiceHealthy),
+		mounts(0, "api", "data", "/data", false),
+		mounts(0, "web", "data", "/data", true),
+		mounts(0, "worker", "data", "/data", false),
+	})
+
+	assert.Equal(t, []Finding{{
+		Rule:     "docker-socket-mount",
+		Severity: SeverityError,
+		Node:     NodeID{Group: 0, Name: "agent"},
+		Message:  "bind mounts the docker socket '/var/run/docker.sock'",
+		Path:     "compose.yaml",
+		Line:     2,
+	}, {
+		Rule:     "database-dependency-not-healthy",
+		Severity: SeverityWarning,
+		Node:     NodeID{Group: 0, Name: "api"},
+		Message:  "depends on database 'db' with condition service_started rather than service_healthy",
+		Path:     "compose.yaml",
+		Line:     5,
+	}, {
+		Rule:     "latest-image-tag",
+		Severity: SeverityWarning,
+		Node:     NodeID{Group: 0, Name: "api"},
+		Message:  "image 'registry:5000/api' has no tag ('latest' is implied)",
+		Path:     "compose.yaml",
+		Line:     5,
+	}, {
+		Rule:     "shared-writable-volume",
+		Severity: SeverityWarning,
+		Node:     NodeID{Group: 0, Name: "api"},
+		Message:  "mounts volume 'data' read-write along with 'worker'",
+		Path:     "compose.yaml",
+		Line:     5,
+	}, {
+		Rule:     "unused-volume",
+		Severity: SeverityWarning,
+		Node:     NodeID{Group: 0, Name: "unused"},
+		Message:  "is declared but not mounted by any service",
+		Path:     "compose.yaml",
+	}, {
+		Rule:     "latest-image-tag",
+		Severity: SeverityWarning,
+		Node:     NodeID{Group: 0, Name: "web"},
+		Message:  "uses the 'latest' tag of image 'nginx:latest'",
+		Path:     "compose.yaml",
+		Line:     12,
+	}, {
+		Rule:     "missing-category-label",
+		Severity: SeverityNote,
+		Node:     NodeID{Group: 0, Name: "web"},
+		Message:  "has no 'graph.node.category' label (categorized as service1)",
+		Path:     "compose.yaml",
+		Line:     12,
+	}}, Lint(g, nil))
+
+	// the severities are configurable, down to disabling a rule
+	findings := Lint(g, map[string]Severity{
+		"latest-image-tag":                SeverityError,
+		"database-dependency-not-healthy": SeverityOff,
+		"docker-socket-mount":             SeverityOff,
+		"shared-writable-volume":          SeverityOff,
+		"missing-category-label":          SeverityOff,
+		"unused-volume":                   SeverityOff,
+	})
+	assert.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.Equal(t, "latest-image-tag", f.Rule)
+		assert.Equal(t, SeverityError, f.Severity)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityOff, SeverityNote, SeverityWarning, SeverityError} {
+		parsed, ok := ParseSeverity(s.String())
+		assert.True(t, ok)
+		assert.Equal(t, s, parsed)
+	}
+
+	_, ok := ParseSeverity("fatal")
+	assert.False(t, ok)
+}
@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// MarkdownOptions control the output of PrintMarkdown
+type MarkdownOptions struct {
+	// Mermaid appends a mermaid flowchart of all the groups to the report
+	Mermaid bool
+}
+
+// PrintMarkdown will print the given graph as a markdown report with a table of services & volumes per group
+func PrintMarkdown(w io.Writer, g Graph, options MarkdownOptions) error {
+	var b strings.Builder
+
+	for gi := range g.Groups {
+		if gi != 0 {
+			b.WriteString("\n")
+		}
+
+		printMarkdownGroup(&b, g, gi)
+	}
+
+	if options.Mermaid {
+		b.WriteString("\n```mermaid\n")
+		if err := printMermaid(&b, g); err != nil {
+			return err
+		}
+		b.WriteString("```\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write markdown report: %w", err)
+	}
+
+	return nil
+}
+
+func printMarkdownGroup(w io.Writer, g Graph, gi int) {
+	group := g.Groups[gi]
+
+	fmt.Fprintf(w, "## %s\n\n", markdownEscape(group.Label))
+
+	fmt.Fprintf(w, "### Services\n\n")
+	fmt.Fprintf(w, "| Service | Label | Category | Dependencies | Mounts |\n")
+	fmt.Fprintf(w, "|---------|-------|----------|--------------|--------|\n")
+
+	var volumes []Node
+
+	for _, node := range group.Nodes {
+		if node.Category == CategoryVolume {
+			volumes = append(volumes, node)
+			continue
+		}
+
+		var dependencies, mounted []string
+
+		for _, e := range g.Outgoing(NodeID{Group: gi, Name: node.Name}) {
+			switch e.Kind {
+			case EdgeKindDependency:
+				dependencies = append(dependencies, markdownMarked(fmt.Sprintf("`%s` (%s)", markdownEscape(e.To.Name), dependencyNotes(e.Condition.String(), e)), e.Diff))
+
+			case EdgeKindVolumeMount:
+				mounted = append(mounted, markdownMarked(fmt.Sprintf("`%s` → `%s` (%s)", markdownEscape(e.To.Name), markdownEscape(e.Target), readWrite(e.ReadOnly)), e.Diff))
+			}
+		}
+
+		fmt.Fprintf(
+			w,
+			"| %s | %s | %s | %s | %s |\n",
+			markdownMarked("`"+markdownEscape(node.Name)+"`", node.Diff),
+			markdownEscape(node.Label),
+			node.Category,
+			strings.Join(dependencies, "<br>"),
+			strings.Join(mounted, "<br>"),
+		)
+	}
+
+	if len(volumes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n### Volumes\n\n")
+	fmt.Fprintf(w, "| Volume | Mounted by |\n")
+	fmt.Fprintf(w, "|--------|------------|\n")
+
+	for _, volume := range volumes {
+		var services []string
+
+		// the services mounting the volume from any group, those of other groups qualified by their label
+		incoming := g.Incoming(NodeID{Group: gi, Name: volume.Name})
+		slices.SortStableFunc(incoming, func(a, b Edge) int {
+			return cmp.Or(cmp.Compare(a.From.Group, b.From.Group), cmp.Compare(a.From.Name, b.From.Name))
+		})
+
+		for _, e := range incoming {
+			if e.Kind != EdgeKindVolumeMount {
+				continue
+			}
+
+			service := e.From.Name
+			if e.From.Group != gi {
+				service = g.Groups[e.From.Group].Label + ":" + service
+			}
+
+			services = append(services, markdownMarked(fmt.Sprintf("`%s` (%s)", markdownEscape(service), readWrite(e.ReadOnly)), e.Diff))
+		}
+
+		fmt.Fprintf(w, "| %s | %s |\n", markdownMarked("`"+markdownEscape(volume.Name)+"`", volume.Diff), strings.Join(services, ", "))
+	}
+}
+
+func readWrite(readOnly bool) string {
+	if readOnly {
+		return "ro"
+	}
+	return "rw"
+}
+
+// dependencyNotes appends the optional & restart qualifiers of the dependency to its condition
+func dependencyNotes(condition string, e Edge) string {
+	notes := []string{condition}
+	if !e.Required {
+		notes = append(notes, "optional")
+	}
+	if e.Restart {
+		notes = append(notes, "restart")
+	}
+	return strings.Join(notes, ", ")
+}
+
+// markdownMarked appends the marker of the added, removed & changed nodes & edges of a combined graph
+func markdownMarked(text string, status DiffStatus) string {
+	if marker := status.marker(); marker != "" {
+		return text + " " + marker
+	}
+	return text
+}
+
+// markdownEscape escapes the characters which would break the table layout
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintMarkdown(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my|service", Category: CategoryService1},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/tmp", true),
+	})
+
+	var b strings.Builder
+
+	require.NoError(t, PrintMarkdown(&b, g, MarkdownOptions{}))
+
+	assert.Equal(t, "## docker-compose.yaml\n"+
+		"\n"+
+		"### Services\n"+
+		"\n"+
+		"| Service | Label | Category | Dependencies | Mounts |\n"+
+		"|---------|-------|----------|--------------|--------|\n"+
+		"| `my-database` | my-database | database |  | `my-volume` → `/var/lib/data` (rw) |\n"+
+		"| `my-service` | my\\|service | service1 | `my-database` (service_healthy) | `my-volume` → `/tmp` (ro) |\n"+
+		"\n"+
+		"### Volumes\n"+
+		"\n"+
+		"| Volume | Mounted by |\n"+
+		"|--------|------------|\n"+
+		"| `my-volume` | `my-database` (rw), `my-service` (ro) |\n",
+		b.String(),
+	)
+
+	b.Reset()
+
+	require.NoError(t, PrintMarkdown(&b, g, MarkdownOptions{Mermaid: true}))
+
+	assert.Contains(t, b.String(), "\n```mermaid\nflowchart TB\n")
+	assert.True(t, strings.HasSuffix(b.String(), "```\n"))
+}
+
+func TestPrintMarkdownCrossGroupMounts(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "storage",
+		Nodes: []Node{
+			{Name: "backup", Category: CategoryTool},
+			{Name: "shared", Category: CategoryVolume},
+		},
+	}, {
+		Label: "app",
+		Nodes: []Node{
+			{Name: "web", Category: CategoryService1},
+		},
+	}}, []Edge{
+		mounts(0, "backup", "shared", "/backup", true),
+		{From: NodeID{Group: 1, Name: "web"}, To: NodeID{Group: 0, Name: "shared"}, Kind: EdgeKindVolumeMount, Target: "/data"},
+	})
+
+	var b strings.Builder
+
+	require.NoError(t, PrintMarkdown(&b, g, MarkdownOptions{}))
+
+	// the services of other groups are listed along with those of the volume's group
+	assert.Contains(t, b.String(), "| `shared` | `backup` (ro), `app:web` (rw) |\n")
+}
@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// PrintMermaid will print the given graph as a mermaid flowchart
+func PrintMermaid(w io.Writer, g Graph) error {
+	var b strings.Builder
+
+	if err := printMermaid(&b, g); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write mermaid flowchart: %w", err)
+	}
+
+	return nil
+}
+
+func printMermaid(w io.Writer, g Graph) error {
+	l := newLayout(g)
+
+	fmt.Fprintf(w, "flowchart TB\n")
+
+	for gi, group := range l.groups {
+		fmt.Fprintf(w, "  subgraph cluster_%d[%s]\n", gi, mermaidQuote(group.label))
+
+		for ni, node := range group.nodes {
+			d, err := g.Theme.nodeDecorations(node.Node)
+			if err != nil {
+				return err
+			}
+
+			open, close := mermaidShape(d)
+
+			fmt.Fprintf(w, "    %s%s%s%s:::%s\n", nodeID(layoutRef{group: gi, node: ni}), open, mermaidQuote(node.Label), close, mermaidClass(node.Node))
+		}
+
+		fmt.Fprintf(w, "  end\n")
+	}
+
+	decorations := make([]EdgeDecorations, len(l.edges))
+
+	for i, e := range l.edges {
+		d, err := edgeDecorations(e.Edge)
+		if err != nil {
+			return err
+		}
+
+		arrow := "-->"
+		if d.arrowhead == ArrowDiamond {
+			arrow = "--o"
+		}
+		// mermaid has no marker at the start of an arrow, so restarts are labelled instead
+		if d.arrowtail == ArrowDot {
+			arrow += "|restart|"
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", nodeID(e.from), arrow, nodeID(e.to))
+
+		decorations[i] = d
+	}
+
+	// mermaid arrows cannot be both dashed & bold, so the edges are styled individually
+	for i, d := range decorations {
+		fmt.Fprintf(w, "  linkStyle %d %s\n", i, mermaidEdgeStyle(d))
+	}
+
+	for _, node := range mermaidClasses(g.Groups) {
+		d, err := g.Theme.nodeDecorations(node)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(
+			w,
+			"  classDef %s fill:%s,stroke:%s,color:%s,stroke-width:%s\n",
+			mermaidClass(node),
+			d.palette.ColorFill.Hex(),
+			d.palette.ColorBorder.Hex(),
+			d.palette.ColorFont.Hex(),
+			mermaidStrokeWidth(d.styles),
+		)
+	}
+
+	return nil
+}
+
+// mermaidClass returns the class of the node: its category, or its status in a combined graph
+func mermaidClass(node Node) string {
+	if node.Diff != DiffNone {
+		return node.Diff.String()
+	}
+	return node.Category.String()
+}
+
+// mermaidClasses returns a node representing each class present in the given groups, ordered by
+// category or status to achieve a reproducible output
+func mermaidClasses(groups []NodeGroup) []Node {
+	if !isDiff(groups) {
+		return legendGroup(groups).Nodes
+	}
+
+	var nodes []Node
+
+	for _, status := range []DiffStatus{DiffUnchanged, DiffAdded, DiffRemoved, DiffChanged} {
+		for _, group := range groups {
+			if slices.ContainsFunc(group.Nodes, func(n Node) bool { return n.Diff == status }) {
+				nodes = append(nodes, Node{Category: CategoryService1, Diff: status})
+				break
+			}
+		}
+	}
+
+	return nodes
+}
+
+// mermaidShape returns the opening & closing brackets for the node shape
+func mermaidShape(d Decorations) (string, string) {
+	switch d.shape {
+	case Cylinder:
+		return "[(", ")]"
+	case Octagon:
+		return "{{", "}}"
+	case Note:
+		return ">", "]"
+	case Diamond:
+		return "{", "}"
+	}
+
+	if slices.Contains(d.styles, Rounded) {
+		return "(", ")"
+	}
+	return "[", "]"
+}
+
+func mermaidEdgeStyle(d EdgeDecorations) string {
+	properties := []string{"stroke:" + d.strokeColor().Hex(), "stroke-width:" + mermaidStrokeWidth(d.styles)}
+
+	switch {
+	case slices.Contains(d.styles, Dashed):
+		properties = append(properties, "stroke-dasharray:6 4")
+	case slices.Contains(d.styles, Dotted):
+		properties = append(properties, "stroke-dasharray:1 3")
+	}
+
+	return strings.Join(properties, ",")
+}
+
+func mermaidStrokeWidth(styles []Style) string {
+	if slices.Contains(styles, Bold) {
+		return "2px"
+	}
+	return "1px"
+}
+
+// mermaidQuote quotes the text, escaping the characters mermaid cannot handle within quotes
+func mermaidQuote(text string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(text) + `"`
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintMermaid(t *testing.T) {
+	var b strings.Builder
+
+	err := PrintMermaid(&b, NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{
+			Name:     "my-database",
+			Label:    "my-database",
+			Category: CategoryDatabase,
+		}, {
+			Name:     "my-service",
+			Label:    `my "service"`,
+			Category: CategoryService1,
+		}},
+	}}, []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, `flowchart TB
+  subgraph cluster_0["docker-compose.yaml"]
+    node_0_0[("my-database")]:::database
+    node_0_1("my #quot;service#quot;"):::service1
+  end
+  node_0_1 --o node_0_0
+  linkStyle 0 stroke:#252525,stroke-width:2px
+  classDef service1 fill:#2171b5,stroke:#084594,color:#ffffff,stroke-width:2px
+  classDef database fill:#238b45,stroke:#005824,color:#ffffff,stroke-width:2px
+`, b.String())
+}
+
+func TestMermaidShape(t *testing.T) {
+	open, close := mermaidShape(categoryDecorations[CategoryScript])
+	assert.Equal(t, ">", open)
+	assert.Equal(t, "]", close)
+
+	open, close = mermaidShape(Decorations{shape: Box})
+	assert.Equal(t, "[", open)
+	assert.Equal(t, "]", close)
+}
@@ -18,37 +18,46 @@ type NodeGroup struct {
 }
 
 type Node struct {
-	Name                string
-	Label               string
-	Category            Category
-	VolumeMounts        []compose.VolumeMount
-	ServiceDependencies []compose.ServiceDependency
+	Name     string
+	Label    string
+	Category Category
+	Labels   map[string]string
+
+	// CategoryConfigured marks a category set by the config (overriding the label & the name)
+	CategoryConfigured bool
+
+	// services only: the image, the published ports & the host paths of the bind mounts
+	Image string
+	Ports []string
+	Binds []string
+
+	// where the service (or the file declaring the volume) is defined
+	Path string
+	Line int
+
+	// Diff marks the nodes of a combined graph (see DiffGraph)
+	Diff DiffStatus
 }
 
 func NodesFromFile(file compose.File) []Node {
 	var nodes []Node
 
 	for name, service := range file.Services {
-		var volumeMounts []compose.VolumeMount
-
-		for _, v := range service.VolumeMounts {
-			// we only care about type "volume" for now
-			if v.Type == compose.VolumeTypeVolume {
-				volumeMounts = append(volumeMounts, v)
-			}
-		}
-
 		label, ok := service.Labels[graphNodeLabel]
 		if !ok {
 			label = name
 		}
 
 		nodes = append(nodes, Node{
-			Name:                name,
-			Label:               label,
-			Category:            DeterminteServiceCategory(name, service.Labels[graphNodeCategory]),
-			VolumeMounts:        volumeMounts,
-			ServiceDependencies: service.ServiceDependencies,
+			Name:     name,
+			Label:    label,
+			Category: DeterminteServiceCategory(name, service.Labels[graphNodeCategory]),
+			Labels:   service.Labels,
+			Image:    service.Image,
+			Ports:    service.Ports,
+			Binds:    bindSources(service.VolumeMounts),
+			Path:     service.Path,
+			Line:     service.Line,
 		})
 	}
 
@@ -57,17 +66,41 @@ func NodesFromFile(file compose.File) []Node {
 			Name:     name,
 			Label:    name,
 			Category: CategoryVolume,
+			Path:     file.Path,
 		})
 	}
 
-	// sort the nodes to achieve a reproducible output
+	// sort the nodes to achieve a reproducible output; the services are collected from a map, so a
+	// service & a volume sharing a name are ordered by kind (services first)
 	slices.SortFunc(nodes, func(a, b Node) int {
-		return cmp.Compare(a.Name, b.Name)
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(boolRank(a.Category == CategoryVolume), boolRank(b.Category == CategoryVolume)),
+		)
 	})
 
 	return nodes
 }
 
+// bindSources returns the host paths of the bind mounts
+func bindSources(mounts []compose.VolumeMount) []string {
+	var sources []string
+	for _, m := range mounts {
+		if m.Type == compose.VolumeTypeBind {
+			sources = append(sources, m.Source)
+		}
+	}
+	return sources
+}
+
+// boolRank orders false before true
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // orderedPresentCategories returns an ordered list of categories that are present in the given slice
 func orderedPresentCategories(groups []NodeGroup) []Category {
 	// bitmap intexed by category
@@ -89,3 +122,25 @@ func orderedPresentCategories(groups []NodeGroup) []Category {
 
 	return present
 }
+
+// legendGroup returns a group with a single node for each category present in the given groups
+func legendGroup(groups []NodeGroup) NodeGroup {
+	legend := NodeGroup{Label: "Legend"}
+
+	// the categories of a combined graph are explained in the palette of the unchanged nodes
+	var status DiffStatus
+	if isDiff(groups) {
+		status = DiffUnchanged
+	}
+
+	for _, category := range orderedPresentCategories(groups) {
+		legend.Nodes = append(legend.Nodes, Node{
+			Name:     category.String(),
+			Label:    category.String(),
+			Category: category,
+			Diff:     status,
+		})
+	}
+
+	return legend
+}
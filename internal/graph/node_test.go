@@ -3,9 +3,39 @@ package graph
 import (
 	"testing"
 
+	"github.com/averche/docker-compose-graph/internal/compose"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNodesFromFile(t *testing.T) {
+	file := compose.File{
+		Services: map[string]compose.Service{
+			"web": {VolumeMounts: []compose.VolumeMount{
+				{Type: compose.VolumeTypeBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"},
+				{Type: compose.VolumeTypeVolume, Source: "assets", Target: "/assets"},
+			}},
+			"cache": {},
+			"data":  {},
+		},
+		Volumes: []string{"data", "assets"},
+		Path:    "compose.yaml",
+	}
+
+	// the order is reproducible, a service preceding a volume of the same name
+	for range 10 {
+		var names []string
+		for _, node := range NodesFromFile(file) {
+			names = append(names, node.Name+":"+node.Category.String())
+		}
+
+		assert.Equal(t, []string{"assets:volume", "cache:service1", "data:service1", "data:volume", "web:service1"}, names)
+	}
+
+	nodes := NodesFromFile(file)
+	assert.Equal(t, []string{"/var/run/docker.sock"}, nodes[4].Binds)
+	assert.Equal(t, "compose.yaml", nodes[0].Path)
+}
+
 func TestOrderedPresentCategories(t *testing.T) {
 	groups := []NodeGroup{{
 		Label: "docker-compose-1.yaml",
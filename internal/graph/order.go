@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StartupOrder returns the services in the order they would be started in, as a list of stages:
+// the services of each stage only depend on the services of the previous stages. Dependencies
+// on undefined services are ignored; a dependency cycle results in an error.
+func StartupOrder(g Graph) ([][]NodeID, error) {
+	if cycles := dependencyCycles(g); len(cycles) != 0 {
+		names := make([]string, 0, len(cycles[0]))
+		for _, id := range cycles[0] {
+			names = append(names, id.Name)
+		}
+		return nil, fmt.Errorf("dependency cycle between %s", strings.Join(names, ", "))
+	}
+
+	stages := make(map[NodeID]int)
+
+	var stage func(id NodeID) int
+	stage = func(id NodeID) int {
+		if s, ok := stages[id]; ok {
+			return s
+		}
+
+		s := 0
+		for _, e := range g.Outgoing(id) {
+			if e.Kind != EdgeKindDependency {
+				continue
+			}
+			if _, ok := g.Node(e.To); !ok {
+				continue
+			}
+			s = max(s, stage(e.To)+1)
+		}
+
+		stages[id] = s
+		return s
+	}
+
+	var order [][]NodeID
+
+	// nodes are sorted within each group, so are the stages
+	for gi, group := range g.Groups {
+		for _, node := range group.Nodes {
+			if node.Category == CategoryVolume {
+				continue
+			}
+
+			id := NodeID{Group: gi, Name: node.Name}
+			s := stage(id)
+
+			for len(order) <= s {
+				order = append(order, nil)
+			}
+			order[s] = append(order[s], id)
+		}
+	}
+
+	return order, nil
+}
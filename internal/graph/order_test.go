@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupOrder(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Category: CategoryService1},
+			{Name: "my-tool", Category: CategoryTool},
+			{Name: "my-ui", Category: CategoryUserInterface},
+			{Name: "my-volume", Category: CategoryVolume},
+		},
+	}, {
+		Nodes: []Node{
+			{Name: "my-script", Category: CategoryScript},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		dependsOn(0, "my-service", "my-missing", compose.ConditionServiceStarted),
+		dependsOn(0, "my-ui", "my-service", compose.ConditionServiceStarted),
+		dependsOn(0, "my-ui", "my-database", compose.ConditionServiceStarted),
+		{
+			Kind:     EdgeKindDependency,
+			From:     NodeID{Group: 1, Name: "my-script"},
+			To:       NodeID{Group: 0, Name: "my-ui"},
+			Required: true,
+		},
+	})
+
+	order, err := StartupOrder(g)
+	require.NoError(t, err)
+	assert.Equal(t, [][]NodeID{
+		{{Group: 0, Name: "my-database"}, {Group: 0, Name: "my-tool"}},
+		{{Group: 0, Name: "my-service"}},
+		{{Group: 0, Name: "my-ui"}},
+		{{Group: 1, Name: "my-script"}},
+	}, order)
+}
+
+func TestStartupOrderCycle(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{{Name: "a"}, {Name: "b"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "a", compose.ConditionServiceStarted),
+	})
+
+	_, err := StartupOrder(g)
+	assert.EqualError(t, err, "dependency cycle between a, b")
+}
@@ -4,37 +4,120 @@ import (
 	"fmt"
 	"io"
 	"strings"
-
-	"github.com/averche/docker-compose-graph/internal/compose"
+	"unicode/utf8"
 )
 
-// Print will print the given nodes as a dot-graph
-func Print(w io.Writer, groups []NodeGroup) {
-	fmt.Fprintf(w, `digraph compose {`+"\n")
-	fmt.Fprintf(w, `  graph [fontname = "arial"];`+"\n")
-	fmt.Fprintf(w, `  node  [fontname = "arial"];`+"\n")
-	fmt.Fprintf(w, `  edge  [fontname = "arial" color = %q];`+"\n", DarkGrey)
+// edgeLabelPathLength is the length beyond which the mount targets are truncated in the edge labels
+const edgeLabelPathLength = 24
+
+// DOTOptions control the output of PrintDOT
+type DOTOptions struct {
+	// EdgeLabels labels the mounts with their targets & the dependencies with their conditions
+	EdgeLabels bool
+
+	// Layout controls the arrangement of the graph by graphviz
+	Layout DOTLayout
+
+	// Annotations attach tooltips & hyperlinks to the nodes & edges
+	Annotations Annotations
+
+	// Heatmap colors the services by the given metric (e.g. their fan-in)
+	Heatmap Metric
+
+	// Failed highlights the given services & the services affected by their failure (see ImpactOf)
+	Failed []NodeID
+}
+
+// nodeHighlights are the decorations of the nodes standing out from their category: the contended
+// volumes, the heatmap of the services & the impact of failed services
+type nodeHighlights struct {
+	contended map[NodeID]bool
+	heatmap   heatmap
+	impact    impactHighlights
+}
+
+// decorate returns the decorations of the node along with its additional attributes
+func (h nodeHighlights) decorate(id NodeID, node Node, d Decorations) (Decorations, string) {
+	if level, ok := h.heatmap.levels[id]; ok {
+		d = heatDecorations(d, level)
+	}
+
+	if p, ok := h.impact.palette(id, node); ok {
+		d.palette = p
+	}
+
+	if h.contended[id] {
+		return contendedDecorations(d), fmt.Sprintf(` penwidth = "%d"`, contendedPenWidth)
+	}
+
+	return d, ""
+}
+
+// Print will print the given graph as a dot-graph
+func Print(w io.Writer, g Graph) error {
+	return PrintDOT(w, g, DOTOptions{})
+}
+
+// PrintDOT will print the given graph as a dot-graph with the given options
+func PrintDOT(w io.Writer, g Graph, options DOTOptions) error {
+	if err := options.Layout.Validate(); err != nil {
+		return err
+	}
+
+	a, err := newAnnotator(g, options.Annotations)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `digraph compose {`+"\n")
+	fmt.Fprintf(&b, `  graph [%s];`+"\n", options.Layout.graphAttributes())
+	fmt.Fprintf(&b, `  node  [%s];`+"\n", options.Layout.fontAttributes())
+	fmt.Fprintf(&b, `  edge  [%s color = %q];`+"\n", options.Layout.fontAttributes(), DarkGrey)
 
 	// subgraphIndex is appended to the names of subgraph clusters
 	var subgraphIndex uint32
 
-	for _, group := range groups {
-		printGroups(w, group, subgraphIndex)
-		subgraphIndex++
+	h := nodeHighlights{
+		contended: contendedVolumes(g),
+		heatmap:   newHeatmap(g, options.Heatmap),
+		impact:    newImpactHighlights(g, options.Failed),
 	}
 
-	printLegend(w, groups, subgraphIndex)
+	if g.Legend != LegendOnly {
+		for _, group := range g.Groups {
+			if err := printGroups(&b, g.Theme, a, group, subgraphIndex, options.Layout.VolumesSink, h); err != nil {
+				return err
+			}
+			subgraphIndex++
+		}
+	}
 
-	for _, group := range groups {
-		for _, node := range group.Nodes {
-			printDependencies(w, node.Name, node.ServiceDependencies, node.VolumeMounts)
+	if g.Legend != LegendHidden {
+		if err := printLegend(&b, g.Theme, g.Groups, legendSamples(g), h, subgraphIndex); err != nil {
+			return err
 		}
 	}
 
-	fmt.Fprintf(w, "}")
+	if g.Legend != LegendOnly {
+		if err := printDependencies(&b, a, g.Edges, options.EdgeLabels); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(&b, "}")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write dot-graph: %w", err)
+	}
+
+	return nil
 }
 
-func printGroups(w io.Writer, group NodeGroup, subgraphIndex uint32) {
+// printGroups prints a dot-graph subgraph cluster with the (annotated & highlighted) nodes of the
+// group; the volumes are optionally pinned to the bottom rank of the cluster
+func printGroups(w io.Writer, theme Theme, a annotator, group NodeGroup, subgraphIndex uint32, volumesSink bool, h nodeHighlights) error {
 	fmt.Fprintf(w, "  subgraph cluster_%d {\n", subgraphIndex)
 	fmt.Fprintf(w, "      label = %q\n", group.Label)
 	fmt.Fprintf(w, "      shape = %q\n", Box)
@@ -42,14 +125,45 @@ func printGroups(w io.Writer, group NodeGroup, subgraphIndex uint32) {
 	fmt.Fprintf(w, "      color = %q\n", DarkGrey)
 
 	for _, node := range group.Nodes {
-		printNode(w, node.Name, node.Label, node.Category, false)
+		// the clusters are numbered after their groups
+		id := NodeID{Group: int(subgraphIndex), Name: node.Name}
+
+		attributes, err := dotNodeAnnotations(a, id, node)
+		if err != nil {
+			return err
+		}
+
+		d, err := theme.nodeDecorations(node)
+		if err != nil {
+			return err
+		}
+
+		d, highlights := h.decorate(id, node, d)
+
+		printDecoratedNode(w, d, node, false, highlights+attributes)
+	}
+
+	if volumesSink {
+		var volumes []string
+		for _, node := range group.Nodes {
+			if node.Category == CategoryVolume {
+				volumes = append(volumes, sanitize(node.Name)+";")
+			}
+		}
+
+		if len(volumes) != 0 {
+			fmt.Fprintf(w, "    { rank = %q; %s }\n", "sink", strings.Join(volumes, " "))
+		}
 	}
 
 	fmt.Fprintf(w, "  }\n")
+
+	return nil
 }
 
-// printLegend prints a dot-graph subgraph with all the node types we encountered
-func printLegend(w io.Writer, groups []NodeGroup, subgraphIndex uint32) {
+// printLegend prints a dot-graph subgraph with all the node types we encountered (along with the
+// highlights, if any), followed by the samples of the edge variants which need explaining
+func printLegend(w io.Writer, theme Theme, groups []NodeGroup, samples []legendEdge, h nodeHighlights, subgraphIndex uint32) error {
 	fmt.Fprintf(w, "  subgraph cluster_%d {\n", subgraphIndex)
 	fmt.Fprintf(w, "      label = %q\n", "Legend")
 	fmt.Fprintf(w, "      shape = %q\n", Box)
@@ -57,65 +171,269 @@ func printLegend(w io.Writer, groups []NodeGroup, subgraphIndex uint32) {
 	fmt.Fprintf(w, "      color = %q\n", DarkGrey)
 
 	// ordered list of categories to achieve a reproducible output
-	for _, category := range orderedPresentCategories(groups) {
-		printNode(w, category.String(), category.String(), category, true)
+	for _, node := range legendGroup(groups).Nodes {
+		d, err := theme.nodeDecorations(node)
+		if err != nil {
+			return err
+		}
+
+		// the categories are explained in the palette of the unaffected services
+		if len(h.impact.failed) != 0 && node.Category != CategoryVolume {
+			d.palette = fadedPalette
+		}
+
+		printDecoratedNode(w, d, node, true, "")
 	}
 
-	fmt.Fprintf(w, "  }\n")
-}
+	// the failed services followed by the couplings of the affected ones, the strongest first
+	if len(h.impact.failed) != 0 {
+		labels := []string{"failed"}
+		palettes := []Palette{failedPalette}
+
+		for _, c := range h.impact.couplings() {
+			labels = append(labels, c.String())
+			palettes = append(palettes, impactPalette(c))
+		}
+
+		for i, label := range labels {
+			node := Node{Name: fmt.Sprintf("legend_impact_%d", i), Label: label, Category: CategoryService1}
+
+			d, err := theme.nodeDecorations(node)
+			if err != nil {
+				return err
+			}
+			d.palette = palettes[i]
+
+			printDecoratedNode(w, d, node, true, "")
+		}
+	}
+
+	if len(h.contended) != 0 {
+		node := Node{Name: "legend_contended_volume", Label: "shared writable volume", Category: CategoryVolume}
+
+		d, err := theme.nodeDecorations(node)
+		if err != nil {
+			return err
+		}
 
-// printNode prints a dot-graph formatted string in the form 'name [style decorators];'
-func printNode(w io.Writer, name string, label string, category Category, small bool) {
-	d, ok := categoryDecorations[category]
-	if !ok {
-		panic(fmt.Sprintf("decorations missing for '%s' category", category))
+		printDecoratedNode(w, contendedDecorations(d), node, true, fmt.Sprintf(` penwidth = "%d"`, contendedPenWidth))
 	}
 
+	// the lowest & highest values of the heatmap
+	if len(h.heatmap.levels) != 0 {
+		values := []int{h.heatmap.min}
+		if h.heatmap.max != h.heatmap.min {
+			values = append(values, h.heatmap.max)
+		}
+
+		for i, value := range values {
+			node := Node{
+				Name:     fmt.Sprintf("legend_heatmap_%d", i),
+				Label:    fmt.Sprintf("%s: %d", h.heatmap.metric, value),
+				Category: CategoryService1,
+			}
+
+			d, err := theme.nodeDecorations(node)
+			if err != nil {
+				return err
+			}
+
+			printDecoratedNode(w, heatDecorations(d, h.heatmap.level(value)), node, true, "")
+		}
+	}
+
+	// each sample is an edge from its label to an (almost) invisible point
+	for i, sample := range samples {
+		name := fmt.Sprintf("legend_edge_%d", i)
+
+		fmt.Fprintf(w, `    %-36s [shape = %-12q fontsize = "8pt"  label = %q];`+"\n", name, "plaintext", sample.label)
+		fmt.Fprintf(w, `    %-36s [shape = %-12q width = "0.05"  color = %q];`+"\n", name+"_to", "point", sample.decorations.strokeColor())
+		printEdge(w, name, name+"_to", sample.decorations, "", "")
+	}
+
+	fmt.Fprintf(w, "  }\n")
+
+	return nil
+}
+
+// printDecoratedNode prints a dot-graph formatted string in the form 'name [style decorators];' with
+// the given decorations, followed by the optional (already formatted) attributes
+func printDecoratedNode(w io.Writer, d Decorations, node Node, small bool, attributes string) {
 	var format string
 	if small {
-		format = `    %-36s [shape = %-12q style = %-24q fillcolor = %-12q color = %-12q fontcolor = %-12q fontsize = "8pt"  label = %q];` + "\n"
+		format = `    %-36s [shape = %-12q style = %-24q fillcolor = %-12q color = %-12q fontcolor = %-12q fontsize = "8pt"  label = %q%s];` + "\n"
 	} else {
-		format = `    %-36s [shape = %-12q style = %-24q fillcolor = %-12q color = %-12q fontcolor = %-12q label = %q];` + "\n"
+		format = `    %-36s [shape = %-12q style = %-24q fillcolor = %-12q color = %-12q fontcolor = %-12q label = %q%s];` + "\n"
 	}
 
 	fmt.Fprintf(
 		w,
 		format,
-		sanitize(name),
+		sanitize(node.Name),
 		d.shape,
 		JoinStyles(d.styles, ","),
 		d.palette.ColorFill,
 		d.palette.ColorBorder,
 		d.palette.ColorFont,
-		label,
+		node.Label,
+		attributes,
 	)
 }
 
-// printDependencies prints the dependency lines formatted in dot-graph arrow (->) notation
-func printDependencies(w io.Writer, name string, serviceDependencies []compose.ServiceDependency, volumeMounts []compose.VolumeMount) {
-	for _, dependency := range serviceDependencies {
-		switch dependency.Condition {
-		case compose.ConditionServiceHealthy:
-			fmt.Fprintf(w, `  %-38s -> %-38s [style="bold" arrowhead="diamond"];`+"\n", sanitize(name), sanitize(dependency.On))
+// dotNodeAnnotations formats the tooltip & hyperlink attributes of the node (if any)
+func dotNodeAnnotations(a annotator, id NodeID, node Node) (string, error) {
+	var attributes string
+
+	if tooltip := a.nodeTooltip(id, node); len(tooltip) != 0 {
+		attributes += fmt.Sprintf(` tooltip = "%s"`, dotLabel(tooltip))
+	}
+
+	url, err := a.nodeURL(id, node)
+	if err != nil {
+		return "", err
+	}
+
+	if url != "" {
+		attributes += fmt.Sprintf(` URL = "%s"`, dotLabel([]string{url}))
+
+		if a.Target != "" {
+			attributes += fmt.Sprintf(` target = "%s"`, dotLabel([]string{a.Target}))
+		}
+	}
+
+	return attributes, nil
+}
+
+// dotEdge is an arrow of the dot-graph along with the lines of its label & tooltip
+type dotEdge struct {
+	from        NodeID
+	to          NodeID
+	decorations EdgeDecorations
+	label       []string
+	tooltip     []string
+}
+
+// printDependencies prints the dependency lines formatted in dot-graph arrow (->) notation; the
+// mounts of the same volume into a service are combined into a single arrow with a multi-line label
+func printDependencies(w io.Writer, a annotator, edges []Edge, labels bool) error {
+	var arrows []dotEdge
+
+	// index of the arrow of each service & volume pair
+	mounts := make(map[[2]NodeID]int)
 
-		case compose.ConditionServiceCompletedSuccessfully:
-			fmt.Fprintf(w, `  %-38s -> %-38s [style="bold"];`+"\n", sanitize(name), sanitize(dependency.On))
+	for _, e := range edges {
+		d, err := edgeDecorations(e)
+		if err != nil {
+			return err
+		}
+
+		var tooltip []string
+		if a.Tooltips {
+			tooltip = []string{a.edgeTooltip(e)}
+		}
 
-		case compose.ConditionServiceStarted:
-			fmt.Fprintf(w, `  %-38s -> %-38s [style="dashed"];`+"\n", sanitize(name), sanitize(dependency.On))
+		if e.Kind == EdgeKindVolumeMount {
+			if i, ok := mounts[[2]NodeID{e.From, e.To}]; ok {
+				arrows[i].label = append(arrows[i].label, edgeLabel(e))
+				arrows[i].tooltip = append(arrows[i].tooltip, tooltip...)
 
-		default:
-			panic(fmt.Sprintf("unexpected dependency condition %q", dependency.Condition))
+				// a read-write mount takes precedence over the read-only ones
+				if !e.ReadOnly {
+					arrows[i].decorations = d
+				}
+				continue
+			}
+			mounts[[2]NodeID{e.From, e.To}] = len(arrows)
 		}
+
+		arrows = append(arrows, dotEdge{
+			from:        e.From,
+			to:          e.To,
+			decorations: d,
+			label:       []string{edgeLabel(e)},
+			tooltip:     tooltip,
+		})
 	}
 
-	for _, v := range volumeMounts {
-		if v.ReadOnly {
-			fmt.Fprintf(w, `  %-38s -> %-38s [style="dashed"];`+"\n", sanitize(name), sanitize(v.Source))
-		} else {
-			fmt.Fprintf(w, `  %-38s -> %-38s [style="bold,dashed"];`+"\n", sanitize(name), sanitize(v.Source))
+	for _, arrow := range arrows {
+		var label string
+		if labels {
+			label = dotLabel(arrow.label)
 		}
+		printEdge(w, arrow.from.Name, arrow.to.Name, arrow.decorations, label, dotLabel(arrow.tooltip))
 	}
+
+	return nil
+}
+
+// dotLabel escapes the lines of a label & joins them with dot-graph line breaks
+func dotLabel(lines []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	escaped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		escaped = append(escaped, escaper.Replace(line))
+	}
+
+	return strings.Join(escaped, `\n`)
+}
+
+// edgeLabel describes the condition of a dependency or the (truncated) target of a mount
+func edgeLabel(e Edge) string {
+	if e.Kind == EdgeKindVolumeMount {
+		return truncatePath(e.Target, edgeLabelPathLength) + " (" + readWrite(e.ReadOnly) + ")"
+	}
+	return dependencyNotes(e.Condition.String(), e)
+}
+
+// truncatePath shortens a path longer than the given length by replacing its leading directories
+// with an ellipsis (e.g. '/var/lib/postgresql/data' => '…/postgresql/data'); the last element is
+// itself truncated if it is too long on its own
+func truncatePath(path string, length int) string {
+	if utf8.RuneCountInString(path) <= length {
+		return path
+	}
+
+	elements := strings.Split(path, "/")
+
+	for i := 1; i < len(elements); i++ {
+		if truncated := "…/" + strings.Join(elements[i:], "/"); utf8.RuneCountInString(truncated) <= length {
+			return truncated
+		}
+	}
+
+	runes := []rune(path)
+
+	return "…" + string(runes[len(runes)-length+1:])
+}
+
+// printEdge prints a single dot-graph arrow (->) with the given decorations, an optional label &
+// an optional tooltip (both already escaped)
+func printEdge(w io.Writer, from, to string, d EdgeDecorations, label, tooltip string) {
+	attributes := []string{fmt.Sprintf("style=%q", JoinStyles(d.styles, ","))}
+
+	if d.arrowhead != "" {
+		attributes = append(attributes, fmt.Sprintf("arrowhead=%q", d.arrowhead))
+	}
+
+	if d.arrowtail != "" {
+		attributes = append(attributes, `dir="both"`, fmt.Sprintf("arrowtail=%q", d.arrowtail))
+	}
+
+	if d.color != "" {
+		attributes = append(attributes, fmt.Sprintf("color=%q", d.color))
+	}
+
+	// the label & the tooltip are already escaped (see dotLabel)
+	if label != "" {
+		attributes = append(attributes, `label="`+label+`"`, `fontsize="8pt"`)
+	}
+
+	if tooltip != "" {
+		attributes = append(attributes, `tooltip="`+tooltip+`"`)
+	}
+
+	fmt.Fprintf(w, `  %-38s -> %-38s [%s];`+"\n", sanitize(from), sanitize(to), strings.Join(attributes, " "))
 }
 
 // dashes are not permitted in dot-graph names
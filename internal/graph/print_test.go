@@ -6,14 +6,24 @@ import (
 
 	"github.com/averche/docker-compose-graph/internal/compose"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestPrintNode(t *testing.T) {
-	var b1, b2, b3 strings.Builder
+func TestPrintDecoratedNode(t *testing.T) {
+	var b1, b2, b3, b4 strings.Builder
 
-	printNode(&b1, "my-service", "my-service", CategoryService1, true)
-	printNode(&b2, "cadence-service", "cadence", CategoryCadence, false)
-	printNode(&b3, "my-tool", "tool1", CategoryTool, true)
+	decorated := func(b *strings.Builder, node Node, small bool, decorate func(Decorations) Decorations, attributes string) {
+		d, err := Theme(nil).nodeDecorations(node)
+		require.NoError(t, err)
+		printDecoratedNode(b, decorate(d), node, small, attributes)
+	}
+
+	same := func(d Decorations) Decorations { return d }
+
+	decorated(&b1, Node{Name: "my-service", Label: "my-service", Category: CategoryService1}, true, same, "")
+	decorated(&b2, Node{Name: "cadence-service", Label: "cadence", Category: CategoryCadence}, false, same, "")
+	decorated(&b3, Node{Name: "my-tool", Label: "tool1", Category: CategoryTool}, true, same, "")
+	decorated(&b4, Node{Name: "my-volume", Label: "my-volume", Category: CategoryVolume}, true, contendedDecorations, ` penwidth = "3"`)
 
 	assert.Equal(
 		t,
@@ -30,34 +40,29 @@ func TestPrintNode(t *testing.T) {
 		`    my_tool                              [shape = "octagon"    style = "rounded,bold,filled"    fillcolor = "/blues8/7"  color = "/blues8/8"  fontcolor = "white"      fontsize = "8pt"  label = "tool1"];`+"\n",
 		b3.String(),
 	)
+	assert.Equal(
+		t,
+		`    my_volume                            [shape = "cylinder"   style = "rounded,bold,filled"    fillcolor = "/greys8/7"  color = "/orrd8/7"   fontcolor = "white"      fontsize = "8pt"  label = "my-volume" penwidth = "3"];`+"\n",
+		b4.String(),
+	)
 }
 
 func TestPrintDependencies(t *testing.T) {
 	var b strings.Builder
 
-	printDependencies(
+	err := printDependencies(
 		&b,
-		"my-service",
-		[]compose.ServiceDependency{{
-			On:        "test-service-2",
-			Condition: compose.ConditionServiceStarted,
-		}, {
-			On:        "test-service-1",
-			Condition: compose.ConditionServiceHealthy,
-		}, {
-			On:        "test-service-3",
-			Condition: compose.ConditionServiceHealthy,
-		}, {
-			On:        "test-service-0",
-			Condition: compose.ConditionServiceCompletedSuccessfully,
-		}},
-		[]compose.VolumeMount{{
-			Type:     compose.VolumeTypeVolume,
-			Source:   "my-volume",
-			Target:   "/var/log",
-			ReadOnly: true,
-		}},
+		annotator{},
+		[]Edge{
+			dependsOn(0, "my-service", "test-service-2", compose.ConditionServiceStarted),
+			dependsOn(0, "my-service", "test-service-1", compose.ConditionServiceHealthy),
+			dependsOn(0, "my-service", "test-service-3", compose.ConditionServiceHealthy),
+			dependsOn(0, "my-service", "test-service-0", compose.ConditionServiceCompletedSuccessfully),
+			mounts(0, "my-service", "my-volume", "/var/log", true),
+		},
+		false,
 	)
+	require.NoError(t, err)
 
 	assert.Contains(
 		t, `
@@ -70,3 +75,54 @@ func TestPrintDependencies(t *testing.T) {
 		b.String(),
 	)
 }
+
+func TestPrintEdgeLabels(t *testing.T) {
+	optional := dependsOn(0, "my-service", "my-cache", compose.ConditionServiceStarted)
+	optional.Required = false
+
+	edges := []Edge{
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		optional,
+		mounts(0, "my-service", "my-volume", "/var/log", true),
+		mounts(0, "my-service", "other-volume", "/other", true),
+		mounts(0, "my-service", "my-volume", "/var/lib/my-service/cache/data", false),
+		mounts(0, "my-service", "my-volume", `C:\data "quoted"`, true),
+	}
+
+	var b strings.Builder
+	require.NoError(t, printDependencies(&b, annotator{}, edges, true))
+
+	assert.Equal(
+		t, `  my_service                             -> my_database                            [style="bold" arrowhead="diamond" label="service_healthy" fontsize="8pt"];
+  my_service                             -> my_cache                               [style="dotted" color="/greys8/5" label="service_started, optional" fontsize="8pt"];
+  my_service                             -> my_volume                              [style="bold,dashed" label="/var/log (ro)\n…/my-service/cache/data (rw)\nC:\\data \"quoted\" (ro)" fontsize="8pt"];
+  my_service                             -> other_volume                           [style="dashed" label="/other (ro)" fontsize="8pt"];
+`,
+		b.String(),
+	)
+
+	// the mounts are merged without labels too
+	b.Reset()
+	require.NoError(t, printDependencies(&b, annotator{}, edges, false))
+
+	assert.Equal(t, 4, strings.Count(b.String(), "->"))
+	assert.NotContains(t, b.String(), "label=")
+}
+
+func TestTruncatePath(t *testing.T) {
+	assert.Equal(t, "/var/lib/postgresql/data", truncatePath("/var/lib/postgresql/data", 24))
+	assert.Equal(t, "…/postgresql/data", truncatePath("/var/lib/postgresql/data", 20))
+	assert.Equal(t, "…/data", truncatePath("/var/lib/postgresql/data", 8))
+	assert.Equal(t, "…stgresql-data", truncatePath("/var/lib/postgresql-data", 14))
+}
+
+func TestPrintErrors(t *testing.T) {
+	var b strings.Builder
+
+	// unknown categories & conditions are reported rather than panicking
+	assert.Error(t, Print(&b, NewGraph([]NodeGroup{{Nodes: []Node{{Name: "my-service", Label: "my-service", Category: categoryCount}}}}, nil)))
+	assert.Error(t, printDependencies(&b, annotator{}, []Edge{dependsOn(0, "my-service", "other", compose.ConditionUnknown)}, false))
+
+	// write errors are propagated
+	assert.ErrorIs(t, Print(failingWriter{}, Graph{}), errWriteFailed)
+}
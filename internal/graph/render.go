@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Renderer renders the graph into a particular output format
+type Renderer interface {
+	Render(w io.Writer, g Graph) error
+}
+
+// RendererFunc is an adapter to allow the use of ordinary functions as renderers
+type RendererFunc func(w io.Writer, g Graph) error
+
+func (f RendererFunc) Render(w io.Writer, g Graph) error {
+	return f(w, g)
+}
+
+// RenderOptions holds the options for all renderers; each renderer uses the relevant subset
+type RenderOptions struct {
+	// Color enables ANSI colors (tree)
+	Color bool
+
+	// Reverse prints the dependents rather than the dependencies (tree)
+	Reverse bool
+
+	// Mermaid appends a mermaid flowchart (markdown)
+	Mermaid bool
+
+	// EdgeLabels labels the edges with the mount targets & dependency conditions (dot)
+	EdgeLabels bool
+
+	// Layout controls the arrangement of the graph by graphviz (dot)
+	Layout DOTLayout
+
+	// Annotations attach tooltips & hyperlinks to the nodes & edges (dot, svg)
+	Annotations Annotations
+
+	// Heatmap colors the services by the given metric (dot)
+	Heatmap Metric
+
+	// Failed highlights the given services & the services affected by their failure (dot)
+	Failed []NodeID
+}
+
+// RendererFactory constructs a renderer with the given options
+type RendererFactory func(options RenderOptions) Renderer
+
+type registration struct {
+	factory    RendererFactory
+	extensions []string
+}
+
+// registry of the renderers keyed by format name
+var registry = make(map[string]registration)
+
+func init() {
+	Register("dot", func(options RenderOptions) Renderer {
+		return RendererFunc(func(w io.Writer, g Graph) error {
+			return PrintDOT(w, g, DOTOptions{
+				EdgeLabels:  options.EdgeLabels,
+				Layout:      options.Layout,
+				Annotations: options.Annotations,
+				Heatmap:     options.Heatmap,
+				Failed:      options.Failed,
+			})
+		})
+	}, ".dot", ".gv")
+	Register("drawio", static(PrintDrawIO), ".drawio")
+	Register("svg", func(options RenderOptions) Renderer {
+		return RendererFunc(func(w io.Writer, g Graph) error {
+			return PrintSVG(w, g, SVGOptions{Annotations: options.Annotations})
+		})
+	}, ".svg")
+	Register("html", static(PrintHTML), ".html", ".htm")
+	Register("mermaid", static(PrintMermaid), ".mmd", ".mermaid")
+	Register("json", static(PrintJSON), ".json")
+	Register("tree", func(options RenderOptions) Renderer {
+		return RendererFunc(func(w io.Writer, g Graph) error {
+			return PrintTree(w, g, TreeOptions{Color: options.Color, Reverse: options.Reverse})
+		})
+	}, ".txt")
+	Register("markdown", func(options RenderOptions) Renderer {
+		return RendererFunc(func(w io.Writer, g Graph) error {
+			return PrintMarkdown(w, g, MarkdownOptions{Mermaid: options.Mermaid})
+		})
+	}, ".md")
+}
+
+// static wraps a renderer function which takes no options
+func static(f RendererFunc) RendererFactory {
+	return func(RenderOptions) Renderer {
+		return f
+	}
+}
+
+// Register makes a renderer available under the given format name & file extensions;
+// registering the same format twice replaces the previous registration
+func Register(format string, factory RendererFactory, extensions ...string) {
+	registry[format] = registration{
+		factory:    factory,
+		extensions: extensions,
+	}
+}
+
+// NewRenderer constructs the renderer registered for the given format
+func NewRenderer(format string, options RenderOptions) (Renderer, error) {
+	r, ok := registry[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s' (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	return r.factory(options), nil
+}
+
+// FormatFromPath returns the format registered for the extension of the given file path
+func FormatFromPath(path string) (string, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "", false
+	}
+
+	for _, format := range Formats() {
+		if slices.Contains(registry[format].extensions, ext) {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// Formats returns the sorted list of the registered formats
+func Formats() []string {
+	return slices.Sorted(maps.Keys(registry))
+}
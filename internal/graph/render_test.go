@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter simulates a closed pipe
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWriteFailed
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range Formats() {
+		r, err := NewRenderer(format, RenderOptions{})
+		require.NoError(t, err, format)
+
+		var b strings.Builder
+		assert.NoError(t, r.Render(&b, Graph{}), format)
+
+		// write errors are propagated by every renderer
+		assert.ErrorIs(t, r.Render(failingWriter{}, Graph{}), errWriteFailed, format)
+	}
+
+	_, err := NewRenderer("unknown", RenderOptions{})
+	assert.ErrorContains(t, err, "unknown format 'unknown'")
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{{
+		path:     "graph.dot",
+		expected: "dot",
+		ok:       true,
+	}, {
+		path:     "some/dir/graph.SVG",
+		expected: "svg",
+		ok:       true,
+	}, {
+		path:     "README.md",
+		expected: "markdown",
+		ok:       true,
+	}, {
+		path:     "graph.mmd",
+		expected: "mermaid",
+		ok:       true,
+	}, {
+		path: "graph",
+		ok:   false,
+	}, {
+		path: "graph.png",
+		ok:   false,
+	}}
+
+	for _, tt := range tests {
+		actual, ok := FormatFromPath(tt.path)
+		assert.Equal(t, tt.ok, ok, tt.path)
+		assert.Equal(t, tt.expected, actual, tt.path)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test", static(func(w io.Writer, g Graph) error {
+		_, err := io.WriteString(w, "test")
+		return err
+	}), ".test")
+	defer delete(registry, "test")
+
+	format, ok := FormatFromPath("graph.test")
+	require.True(t, ok)
+
+	r, err := NewRenderer(format, RenderOptions{})
+	require.NoError(t, err)
+
+	var b strings.Builder
+	require.NoError(t, r.Render(&b, Graph{}))
+	assert.Equal(t, "test", b.String())
+}
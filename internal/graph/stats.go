@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+// Stats are the aggregate metrics of a graph
+type Stats struct {
+	Groups       int
+	Services     int
+	Volumes      int
+	Dependencies int
+	Mounts       int
+
+	// Stages is the number of startup stages, i.e. the length of the longest dependency chain
+	Stages int
+
+	// HealthGates is the number of dependencies waiting for their service to be healthy
+	HealthGates int
+
+	// LongestChain is a longest chain of dependencies, from a dependent down to a service without
+	// dependencies; Depth is its number of dependencies
+	LongestChain []NodeID
+	Depth        int
+
+	// Orphans are the services without dependencies, dependents or mounts
+	Orphans []NodeID
+
+	// UnusedVolumes are the volumes mounted by no service
+	UnusedVolumes []NodeID
+
+	// Components are the strongly connected components of the dependencies which form cycles
+	Components [][]NodeID
+
+	// PerService holds the fan-in & fan-out of each service
+	PerService []ServiceStats
+}
+
+// ServiceStats are the metrics of a service
+type ServiceStats struct {
+	Node NodeID
+
+	// FanIn is the number of services depending on the service
+	FanIn int
+
+	// FanOut is the number of dependencies of the service
+	FanOut int
+}
+
+// ComputeStats computes the metrics of the graph; the number of stages & the longest chain are
+// left empty when the dependencies form a cycle
+func ComputeStats(g Graph) Stats {
+	s := Stats{Groups: len(g.Groups)}
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category != CategoryVolume {
+			s.Services++
+			if len(g.Outgoing(id)) == 0 && len(g.Incoming(id)) == 0 {
+				s.Orphans = append(s.Orphans, id)
+			}
+			return
+		}
+
+		s.Volumes++
+		if len(g.Incoming(id)) == 0 {
+			s.UnusedVolumes = append(s.UnusedVolumes, id)
+		}
+	})
+
+	for _, e := range g.Edges {
+		switch e.Kind {
+		case EdgeKindDependency:
+			s.Dependencies++
+			if e.Condition == compose.ConditionServiceHealthy {
+				s.HealthGates++
+			}
+		case EdgeKindVolumeMount:
+			s.Mounts++
+		}
+	}
+
+	if order, err := StartupOrder(g); err == nil {
+		s.Stages = len(order)
+		s.LongestChain = longestChain(g)
+		s.Depth = max(len(s.LongestChain)-1, 0)
+	}
+
+	s.Components = dependencyComponents(g)
+	s.PerService = serviceStats(g)
+
+	return s
+}
+
+// serviceStats returns the fan-in & fan-out of the services, ordered by group & name
+func serviceStats(g Graph) []ServiceStats {
+	var stats []ServiceStats
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category == CategoryVolume {
+			return
+		}
+
+		s := ServiceStats{Node: id}
+
+		for _, e := range g.Incoming(id) {
+			if e.Kind == EdgeKindDependency {
+				s.FanIn++
+			}
+		}
+
+		for _, e := range g.Outgoing(id) {
+			if e.Kind == EdgeKindDependency {
+				s.FanOut++
+			}
+		}
+
+		stats = append(stats, s)
+	})
+
+	return stats
+}
+
+// longestChain returns the first of the longest chains of dependencies (in the order of the nodes);
+// the dependencies must not form a cycle
+func longestChain(g Graph) []NodeID {
+	var (
+		lengths = make(map[NodeID]int)
+		next    = make(map[NodeID]NodeID)
+	)
+
+	var length func(id NodeID) int
+	length = func(id NodeID) int {
+		if l, ok := lengths[id]; ok {
+			return l
+		}
+
+		l := 1
+		for _, e := range g.Outgoing(id) {
+			if e.Kind != EdgeKindDependency {
+				continue
+			}
+			if _, ok := g.Node(e.To); !ok {
+				continue
+			}
+			if to := length(e.To) + 1; to > l {
+				l, next[id] = to, e.To
+			}
+		}
+
+		lengths[id] = l
+		return l
+	}
+
+	var (
+		start NodeID
+		best  int
+	)
+
+	g.eachNode(func(id NodeID, node Node) {
+		if node.Category == CategoryVolume {
+			return
+		}
+		if l := length(id); l > best {
+			start, best = id, l
+		}
+	})
+
+	if best == 0 {
+		return nil
+	}
+
+	chain := []NodeID{start}
+	for id, ok := next[start]; ok; id, ok = next[id] {
+		chain = append(chain, id)
+	}
+
+	return chain
+}
+
+// dependencyComponents returns the strongly connected components of the dependencies (Tarjan's
+// algorithm) which form cycles, i.e. span several services or a service depending on itself; the
+// services of each component & the components are ordered by group & name
+func dependencyComponents(g Graph) [][]NodeID {
+	var (
+		components [][]NodeID
+		stack      []NodeID
+		onStack    = make(map[NodeID]bool)
+		index      = make(map[NodeID]int)
+		low        = make(map[NodeID]int)
+	)
+
+	var connect func(id NodeID)
+	connect = func(id NodeID) {
+		index[id] = len(index)
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		looped := false
+
+		for _, e := range g.Outgoing(id) {
+			if e.Kind != EdgeKindDependency {
+				continue
+			}
+			if _, ok := g.Node(e.To); !ok {
+				continue
+			}
+
+			looped = looped || e.To == id
+
+			if _, ok := index[e.To]; !ok {
+				connect(e.To)
+				low[id] = min(low[id], low[e.To])
+			} else if onStack[e.To] {
+				low[id] = min(low[id], index[e.To])
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+
+		i := slices.Index(stack, id)
+		component := slices.Clone(stack[i:])
+		stack = stack[:i]
+
+		for _, member := range component {
+			onStack[member] = false
+		}
+
+		if len(component) > 1 || looped {
+			slices.SortFunc(component, compareNodeIDs)
+			components = append(components, component)
+		}
+	}
+
+	g.eachNode(func(id NodeID, node Node) {
+		if _, ok := index[id]; !ok && node.Category != CategoryVolume {
+			connect(id)
+		}
+	})
+
+	slices.SortFunc(components, func(a, b []NodeID) int {
+		return compareNodeIDs(a[0], b[0])
+	})
+
+	return components
+}
+
+// compareNodeIDs orders the nodes by group & name
+func compareNodeIDs(a, b NodeID) int {
+	return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Name, b.Name))
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStats(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Category: CategoryService1},
+			{Name: "my-volume", Category: CategoryVolume},
+		},
+	}, {
+		Nodes: []Node{
+			{Name: "my-tool", Category: CategoryTool},
+			{Name: "unused", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceStarted),
+	})
+
+	id := func(group int, name string) NodeID { return NodeID{Group: group, Name: name} }
+
+	assert.Equal(t, Stats{
+		Groups:        2,
+		Services:      3,
+		Volumes:       2,
+		Dependencies:  1,
+		Mounts:        1,
+		Stages:        2,
+		LongestChain:  []NodeID{id(0, "my-service"), id(0, "my-database")},
+		Depth:         1,
+		Orphans:       []NodeID{id(1, "my-tool")},
+		UnusedVolumes: []NodeID{id(1, "unused")},
+		PerService: []ServiceStats{
+			{Node: id(0, "my-database"), FanIn: 1},
+			{Node: id(0, "my-service"), FanOut: 1},
+			{Node: id(1, "my-tool")},
+		},
+	}, ComputeStats(g))
+}
+
+func TestComputeStatsCycles(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{
+			{Name: "a", Category: CategoryService1},
+			{Name: "b", Category: CategoryService1},
+			{Name: "c", Category: CategoryService1},
+			{Name: "d", Category: CategoryService1},
+			{Name: "e", Category: CategoryService1},
+		},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceHealthy),
+		dependsOn(0, "b", "c", compose.ConditionServiceStarted),
+		dependsOn(0, "c", "a", compose.ConditionServiceHealthy),
+		dependsOn(0, "c", "d", compose.ConditionServiceCompletedSuccessfully),
+		dependsOn(0, "e", "e", compose.ConditionServiceStarted),
+	})
+
+	id := func(name string) NodeID { return NodeID{Group: 0, Name: name} }
+
+	s := ComputeStats(g)
+
+	assert.Equal(t, 2, s.HealthGates)
+	assert.Equal(t, [][]NodeID{{id("a"), id("b"), id("c")}, {id("e")}}, s.Components)
+	assert.Equal(t, ServiceStats{Node: id("c"), FanIn: 1, FanOut: 2}, s.PerService[2])
+
+	// the chains are unbounded
+	assert.Zero(t, s.Stages)
+	assert.Nil(t, s.LongestChain)
+	assert.Zero(t, s.Depth)
+}
@@ -1,6 +1,11 @@
 package graph
 
-import "strings"
+import (
+	"slices"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
 
 type Color string
 
@@ -11,6 +16,7 @@ const (
 	Red    Color = "/orrd8/7"
 	Grey   Color = "/greys8/7"
 	Purple Color = "/bupu8/7"
+	Orange Color = "/oranges8/7"
 
 	DarkBlue   Color = "/blues8/8"
 	DarkGreen  Color = "/bugn8/8"
@@ -18,10 +24,42 @@ const (
 	DarkRed    Color = "/orrd8/8"
 	DarkGrey   Color = "/greys8/8"
 	DarkPurple Color = "/bupu8/8"
+	DarkOrange Color = "/oranges8/8"
+
+	LightGrey Color = "/greys8/5"
 
 	White Color = "white"
 )
 
+// colorHex maps the graphviz color scheme references above to RGB values for
+// renderers that do not understand graphviz color schemes
+var colorHex = map[Color]string{
+	Blue:       "#2171b5",
+	Green:      "#238b45",
+	Teal:       "#35978f",
+	Red:        "#d7301f",
+	Grey:       "#525252",
+	Purple:     "#88419d",
+	Orange:     "#d94801",
+	DarkBlue:   "#084594",
+	DarkGreen:  "#005824",
+	DarkTeal:   "#01665e",
+	DarkRed:    "#990000",
+	DarkGrey:   "#252525",
+	DarkPurple: "#6e016b",
+	DarkOrange: "#8c2d04",
+	LightGrey:  "#969696",
+	White:      "#ffffff",
+}
+
+// Hex returns the RGB representation of the color in '#rrggbb' format
+func (c Color) Hex() string {
+	if h, ok := colorHex[c]; ok {
+		return h
+	}
+	return string(c)
+}
+
 type Shape string
 
 const (
@@ -42,6 +80,14 @@ const (
 	Dashed  Style = "dashed"
 )
 
+type Arrow string
+
+const (
+	ArrowNormal  Arrow = "normal"
+	ArrowDiamond Arrow = "diamond"
+	ArrowDot     Arrow = "dot"
+)
+
 type Palette struct {
 	ColorFill   Color
 	ColorBorder Color
@@ -54,6 +100,58 @@ type Decorations struct {
 	palette Palette
 }
 
+type EdgeDecorations struct {
+	styles    []Style
+	arrowhead Arrow
+	arrowtail Arrow // marker at the dependent end, if any
+	color     Color // DarkGrey if not set
+}
+
+// strokeColor returns the color of the edge line
+func (d EdgeDecorations) strokeColor() Color {
+	if d.color == "" {
+		return DarkGrey
+	}
+	return d.color
+}
+
+// dependencyDecorations returns the edge decorations for a service dependency with the given condition
+func dependencyDecorations(condition compose.Condition) (EdgeDecorations, bool) {
+	switch condition {
+	case compose.ConditionServiceHealthy:
+		return EdgeDecorations{styles: []Style{Bold}, arrowhead: ArrowDiamond}, true
+
+	case compose.ConditionServiceCompletedSuccessfully:
+		return EdgeDecorations{styles: []Style{Bold}}, true
+
+	case compose.ConditionServiceStarted:
+		return EdgeDecorations{styles: []Style{Dashed}}, true
+	}
+
+	return EdgeDecorations{}, false
+}
+
+// optionalDecorations fades the decorations of an optional dependency: dotted rather than dashed & greyed out
+func optionalDecorations(d EdgeDecorations) EdgeDecorations {
+	d.styles = append(slices.DeleteFunc(slices.Clone(d.styles), func(s Style) bool { return s == Dashed }), Dotted)
+	d.color = LightGrey
+	return d
+}
+
+// restartDecorations marks the dependent end of a dependency propagating the restarts of the dependency
+func restartDecorations(d EdgeDecorations) EdgeDecorations {
+	d.arrowtail = ArrowDot
+	return d
+}
+
+// mountDecorations returns the edge decorations for a volume mount
+func mountDecorations(readOnly bool) EdgeDecorations {
+	if readOnly {
+		return EdgeDecorations{styles: []Style{Dashed}}
+	}
+	return EdgeDecorations{styles: []Style{Bold, Dashed}}
+}
+
 func JoinStyles(styles []Style, sep string) string {
 	var b strings.Builder
 
@@ -0,0 +1,393 @@
+package graph
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	svgMargin          = 20
+	svgFontSize        = 12
+	svgFontSizeSmall   = 9
+	svgCylinderRadius  = 6
+	svgOctagonCorner   = 10
+	svgNoteCorner      = 10
+	svgArrowMarker     = "arrow-normal"
+	svgDiamondMarker   = "arrow-diamond"
+	svgDotMarker       = "arrow-dot"
+	svgDashArray       = "6,4"
+	svgDotArray        = "1,3"
+	svgStrokeWidth     = 1
+	svgStrokeWidthBold = 2
+	svgSampleGap       = 6
+)
+
+// SVGOptions control the output of PrintSVG
+type SVGOptions struct {
+	// Annotations attach tooltips & hyperlinks to the nodes & edges
+	Annotations Annotations
+}
+
+// PrintSVG will print the given graph as an svg image, arranged by the built-in layout engine
+func PrintSVG(w io.Writer, g Graph, options SVGOptions) error {
+	svg, err := renderSVG(g, options.Annotations)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+svg); err != nil {
+		return fmt.Errorf("could not write svg image: %w", err)
+	}
+
+	return nil
+}
+
+// renderSVG lays out the given graph (followed by the legend) and draws it as an svg element;
+// nodes & edges carry 'data-' attributes describing the graph for interactive viewers
+func renderSVG(g Graph, annotations Annotations) (string, error) {
+	a, err := newAnnotator(g, annotations)
+	if err != nil {
+		return "", err
+	}
+
+	l := computeLegendLayout(g)
+
+	var b strings.Builder
+
+	width, height := l.width+2*svgMargin, l.height+2*svgMargin
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Arial, sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `  <defs>`+"\n")
+	fmt.Fprintf(&b, `    <marker id="%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n", svgArrowMarker, DarkGrey.Hex())
+	fmt.Fprintf(&b, `    <marker id="%s" viewBox="0 0 12 12" refX="12" refY="6" markerWidth="10" markerHeight="10" orient="auto"><path d="M0,6 L6,0 L12,6 L6,12 z" fill="%s"/></marker>`+"\n", svgDiamondMarker, DarkGrey.Hex())
+	fmt.Fprintf(&b, `    <marker id="%s" viewBox="0 0 10 10" refX="5" refY="5" markerWidth="6" markerHeight="6" orient="auto"><circle cx="5" cy="5" r="4" fill="%s"/></marker>`+"\n", svgDotMarker, DarkGrey.Hex())
+	fmt.Fprintf(&b, `  </defs>`+"\n")
+	fmt.Fprintf(&b, `  <g transform="translate(%d,%d)">`+"\n", svgMargin, svgMargin)
+
+	for _, group := range l.groups {
+		printSVGGroup(&b, group)
+	}
+
+	for _, e := range l.edges {
+		if err := printSVGEdge(&b, &l, a, e); err != nil {
+			return "", err
+		}
+	}
+
+	for gi, group := range l.groups {
+		for ni := range group.nodes {
+			if err := printSVGNode(&b, &l, g.Theme, a, layoutRef{group: gi, node: ni}, l.isLegend(gi)); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, sample := range l.samples {
+		printSVGSample(&b, sample)
+	}
+
+	fmt.Fprintf(&b, `  </g>`+"\n")
+	fmt.Fprintf(&b, `</svg>`+"\n")
+
+	return b.String(), nil
+}
+
+// printSVGGroup prints the cluster surrounding the group in the same style as the dot-graph subgraphs
+func printSVGGroup(w io.Writer, group layoutGroup) {
+	fmt.Fprintf(w, `    <g class="cluster">`+"\n")
+	fmt.Fprintf(
+		w,
+		`      <rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="none" stroke="%s" %s/>`+"\n",
+		group.x,
+		group.y,
+		group.width,
+		group.height,
+		DarkGrey.Hex(),
+		svgStrokeAttributes([]Style{Rounded, Bold, Dashed}),
+	)
+	fmt.Fprintf(
+		w,
+		`      <text x="%d" y="%d" text-anchor="middle" font-size="%d" font-weight="bold" fill="%s">%s</text>`+"\n",
+		group.x+group.width/2,
+		group.y+layoutGroupHeader/2+svgFontSize/2+4,
+		svgFontSize,
+		DarkGrey.Hex(),
+		html.EscapeString(group.label),
+	)
+	fmt.Fprintf(w, `    </g>`+"\n")
+}
+
+// printSVGNode prints the node shape along with its label, its tooltip & its hyperlink; legend
+// nodes are printed in a smaller font & without annotations
+func printSVGNode(w io.Writer, l *layout, theme Theme, a annotator, ref layoutRef, legend bool) error {
+	node := l.node(ref)
+
+	d, err := theme.nodeDecorations(node.Node)
+	if err != nil {
+		return err
+	}
+
+	var tooltip []string
+	var url string
+
+	if !legend {
+		id := NodeID{Group: ref.group, Name: node.Name}
+
+		tooltip = a.nodeTooltip(id, node.Node)
+
+		if url, err = a.nodeURL(id, node.Node); err != nil {
+			return err
+		}
+	}
+
+	x, y := l.absolute(ref)
+	width, height := node.width, node.height
+
+	fill := "none"
+	if slices.Contains(d.styles, Filled) {
+		fill = d.palette.ColorFill.Hex()
+	}
+
+	attributes := fmt.Sprintf(`fill="%s" stroke="%s" %s`, fill, d.palette.ColorBorder.Hex(), svgStrokeAttributes(d.styles))
+
+	class := "node"
+	if legend {
+		class = "legend-node"
+	}
+
+	fmt.Fprintf(
+		w,
+		`    <g class="%s" id="%s" data-name="%s" data-category="%s">`+"\n",
+		class,
+		nodeID(ref),
+		html.EscapeString(node.Name),
+		node.Category,
+	)
+
+	if len(tooltip) != 0 {
+		fmt.Fprintf(w, `      <title>%s</title>`+"\n", html.EscapeString(strings.Join(tooltip, "\n")))
+	}
+
+	if url != "" {
+		fmt.Fprintf(w, `      <a href="%s"%s>`+"\n", html.EscapeString(url), svgTarget(a.Target))
+	}
+
+	switch d.shape {
+	case Cylinder:
+		r := svgCylinderRadius
+		fmt.Fprintf(
+			w,
+			`      <path d="M%d,%d L%d,%d A%d,%d 0 0,0 %d,%d L%d,%d A%d,%d 0 0,0 %d,%d z" %s/>`+"\n",
+			x, y+r,
+			x, y+height-r,
+			width/2, r, x+width, y+height-r,
+			x+width, y+r,
+			width/2, r, x, y+r,
+			attributes,
+		)
+		fmt.Fprintf(w, `      <path d="M%d,%d A%d,%d 0 0,0 %d,%d" fill="none" stroke="%s" %s/>`+"\n", x, y+r, width/2, r, x+width, y+r, d.palette.ColorBorder.Hex(), svgStrokeAttributes(d.styles))
+
+	case Octagon:
+		c := svgOctagonCorner
+		fmt.Fprintf(
+			w,
+			`      <polygon points="%d,%d %d,%d %d,%d %d,%d %d,%d %d,%d %d,%d %d,%d" %s/>`+"\n",
+			x+c, y,
+			x+width-c, y,
+			x+width, y+c,
+			x+width, y+height-c,
+			x+width-c, y+height,
+			x+c, y+height,
+			x, y+height-c,
+			x, y+c,
+			attributes,
+		)
+
+	case Note:
+		c := svgNoteCorner
+		fmt.Fprintf(w, `      <polygon points="%d,%d %d,%d %d,%d %d,%d %d,%d" %s/>`+"\n", x, y, x+width-c, y, x+width, y+c, x+width, y+height, x, y+height, attributes)
+		fmt.Fprintf(w, `      <polyline points="%d,%d %d,%d %d,%d" fill="none" stroke="%s" %s/>`+"\n", x+width-c, y, x+width-c, y+c, x+width, y+c, d.palette.ColorBorder.Hex(), svgStrokeAttributes(d.styles))
+
+	case Diamond:
+		fmt.Fprintf(w, `      <polygon points="%d,%d %d,%d %d,%d %d,%d" %s/>`+"\n", x+width/2, y, x+width, y+height/2, x+width/2, y+height, x, y+height/2, attributes)
+
+	default:
+		rx := 0
+		if slices.Contains(d.styles, Rounded) {
+			rx = 6
+		}
+		fmt.Fprintf(w, `      <rect x="%d" y="%d" width="%d" height="%d" rx="%d" %s/>`+"\n", x, y, width, height, rx, attributes)
+	}
+
+	fontSize := svgFontSize
+	if legend {
+		fontSize = svgFontSizeSmall
+	}
+
+	fmt.Fprintf(
+		w,
+		`      <text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" font-size="%d" fill="%s">%s</text>`+"\n",
+		x+width/2,
+		y+height/2,
+		fontSize,
+		d.palette.ColorFont.Hex(),
+		html.EscapeString(node.Label),
+	)
+
+	if url != "" {
+		fmt.Fprintf(w, `      </a>`+"\n")
+	}
+
+	fmt.Fprintf(w, `    </g>`+"\n")
+
+	return nil
+}
+
+// svgTarget formats the target attribute of a hyperlink (if any)
+func svgTarget(target string) string {
+	if target == "" {
+		return ""
+	}
+	return fmt.Sprintf(` target="%s"`, html.EscapeString(target))
+}
+
+// printSVGEdge prints the edge as a path through its bends, clipped to the borders of the nodes,
+// along with its tooltip
+func printSVGEdge(w io.Writer, l *layout, a annotator, e layoutEdge) error {
+	decorations, err := edgeDecorations(e.Edge)
+	if err != nil {
+		return err
+	}
+
+	fx, fy := l.absolute(e.from)
+	tx, ty := l.absolute(e.to)
+
+	from, to := l.node(e.from), l.node(e.to)
+
+	source := point{x: fx + from.width/2, y: fy + from.height/2}
+	target := point{x: tx + to.width/2, y: ty + to.height/2}
+
+	if e.from == e.to {
+		return nil // self-references are not drawn
+	}
+
+	points := []point{source}
+	points = append(points, e.bends...)
+	points = append(points, target)
+
+	points[0] = clip(points[0], points[1], from.width, from.height)
+	points[len(points)-1] = clip(points[len(points)-1], points[len(points)-2], to.width, to.height)
+
+	var d strings.Builder
+
+	for i, p := range points {
+		if i == 0 {
+			fmt.Fprintf(&d, "M%d,%d", p.x, p.y)
+		} else {
+			fmt.Fprintf(&d, " L%d,%d", p.x, p.y)
+		}
+	}
+
+	// the path is self-closing unless it holds the tooltip
+	end := "/>"
+	if tooltip := a.edgeTooltip(e.Edge); tooltip != "" {
+		end = "><title>" + html.EscapeString(tooltip) + "</title></path>"
+	}
+
+	fmt.Fprintf(
+		w,
+		`    <path class="edge" data-from="%s" data-to="%s" d="%s" fill="none" %s%s`+"\n",
+		nodeID(e.from),
+		nodeID(e.to),
+		d.String(),
+		svgEdgeAttributes(decorations),
+		end,
+	)
+
+	return nil
+}
+
+// printSVGSample prints the legend edge sample as a short line followed by its label
+func printSVGSample(w io.Writer, sample layoutSample) {
+	fmt.Fprintf(w, `    <g class="legend-edge">`+"\n")
+	fmt.Fprintf(
+		w,
+		`      <path d="M%d,%d L%d,%d" fill="none" %s/>`+"\n",
+		sample.x,
+		sample.y,
+		sample.x+layoutSampleWidth,
+		sample.y,
+		svgEdgeAttributes(sample.decorations),
+	)
+	fmt.Fprintf(
+		w,
+		`      <text x="%d" y="%d" dominant-baseline="central" font-size="%d" fill="%s">%s</text>`+"\n",
+		sample.x+layoutSampleWidth+svgSampleGap,
+		sample.y,
+		svgFontSizeSmall,
+		DarkGrey.Hex(),
+		html.EscapeString(sample.label),
+	)
+	fmt.Fprintf(w, `    </g>`+"\n")
+}
+
+// svgEdgeAttributes translates the edge decorations into svg stroke & marker attributes
+func svgEdgeAttributes(d EdgeDecorations) string {
+	marker := svgArrowMarker
+	if d.arrowhead == ArrowDiamond {
+		marker = svgDiamondMarker
+	}
+
+	attributes := fmt.Sprintf(`stroke="%s" %s marker-end="url(#%s)"`, d.strokeColor().Hex(), svgStrokeAttributes(d.styles), marker)
+
+	if d.arrowtail == ArrowDot {
+		attributes += fmt.Sprintf(` marker-start="url(#%s)"`, svgDotMarker)
+	}
+
+	return attributes
+}
+
+// svgStrokeAttributes translates the dot-graph styles into svg stroke attributes
+func svgStrokeAttributes(styles []Style) string {
+	attributes := []string{fmt.Sprintf(`stroke-width="%d"`, svgStrokeWidth)}
+
+	for _, s := range styles {
+		switch s {
+		case Bold:
+			attributes[0] = fmt.Sprintf(`stroke-width="%d"`, svgStrokeWidthBold)
+		case Dashed:
+			attributes = append(attributes, fmt.Sprintf(`stroke-dasharray="%s"`, svgDashArray))
+		case Dotted:
+			attributes = append(attributes, fmt.Sprintf(`stroke-dasharray="%s"`, svgDotArray))
+		}
+	}
+
+	return strings.Join(attributes, " ")
+}
+
+// clip moves the center of a box with the given dimensions to its border in the direction of the given point
+func clip(center, towards point, width, height int) point {
+	dx, dy := float64(towards.x-center.x), float64(towards.y-center.y)
+
+	if dx == 0 && dy == 0 {
+		return center
+	}
+
+	// the smallest scale at which the line leaves the box
+	scale := math.Inf(1)
+	if dx != 0 {
+		scale = math.Min(scale, float64(width)/2/math.Abs(dx))
+	}
+	if dy != 0 {
+		scale = math.Min(scale, float64(height)/2/math.Abs(dy))
+	}
+
+	return point{
+		x: center.x + int(math.Round(dx*scale)),
+		y: center.y + int(math.Round(dy*scale)),
+	}
+}
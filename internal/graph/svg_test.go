@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the snapshots in testdata")
+
+func TestPrintSVG(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "simple.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-init-script", Label: "my-init-script", Category: CategoryScript},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-tool", Label: "my-tool", Category: CategoryTool},
+			{Name: "my-vault", Label: "my-vault", Category: CategoryVault},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/postgresql/data", false),
+		dependsOn(0, "my-init-script", "my-service", compose.ConditionServiceCompletedSuccessfully),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/tmp", true),
+	})
+
+	var b strings.Builder
+
+	require.NoError(t, PrintSVG(&b, g, SVGOptions{}))
+
+	snapshot := filepath.Join("testdata", "simple.svg")
+
+	if *update {
+		require.NoError(t, os.WriteFile(snapshot, []byte(b.String()), 0o644))
+	}
+
+	expected, err := os.ReadFile(snapshot)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), b.String())
+}
+
+func TestSVGStrokeAttributes(t *testing.T) {
+	assert.Equal(t, `stroke-width="1"`, svgStrokeAttributes(nil))
+	assert.Equal(t, `stroke-width="2"`, svgStrokeAttributes([]Style{Rounded, Bold, Filled}))
+	assert.Equal(t, `stroke-width="2" stroke-dasharray="6,4"`, svgStrokeAttributes([]Style{Bold, Dashed}))
+	assert.Equal(t, `stroke-width="1" stroke-dasharray="1,3"`, svgStrokeAttributes([]Style{Dotted}))
+}
+
+func TestClip(t *testing.T) {
+	// straight down
+	assert.Equal(t, point{x: 50, y: 70}, clip(point{x: 50, y: 50}, point{x: 50, y: 150}, 100, 40))
+
+	// straight left
+	assert.Equal(t, point{x: 0, y: 50}, clip(point{x: 50, y: 50}, point{x: -100, y: 50}, 100, 40))
+
+	// diagonal, leaving through the bottom border
+	assert.Equal(t, point{x: 70, y: 70}, clip(point{x: 50, y: 50}, point{x: 150, y: 150}, 100, 40))
+
+	// same point
+	assert.Equal(t, point{x: 50, y: 50}, clip(point{x: 50, y: 50}, point{x: 50, y: 50}, 100, 40))
+}
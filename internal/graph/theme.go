@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Theme maps each category to its decorations
+type Theme map[Category]Decorations
+
+// DefaultTheme is used when the graph does not specify a theme
+var DefaultTheme = Theme(categoryDecorations)
+
+// MonochromeTheme keeps the shapes & styles of the default theme without the colors (e.g. for printing)
+var MonochromeTheme = func() Theme {
+	t := make(Theme, len(categoryDecorations))
+	for category, d := range categoryDecorations {
+		d.palette = Palette{ColorFill: White, ColorBorder: DarkGrey, ColorFont: DarkGrey}
+		t[category] = d
+	}
+	return t
+}()
+
+var themes = map[string]Theme{
+	"default":    DefaultTheme,
+	"monochrome": MonochromeTheme,
+}
+
+// LookupTheme returns the built-in theme with the given name
+func LookupTheme(name string) (Theme, error) {
+	t, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(Themes(), ", "))
+	}
+	return t, nil
+}
+
+// Themes returns the sorted list of the built-in theme names
+func Themes() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// decorations returns the decorations for the given category, falling back onto the default theme
+func (t Theme) decorations(category Category) (Decorations, error) {
+	if t == nil {
+		t = DefaultTheme
+	}
+
+	d, ok := t[category]
+	if !ok {
+		return Decorations{}, fmt.Errorf("decorations missing for '%s' category", category)
+	}
+
+	return d, nil
+}
+
+// nodeDecorations returns the decorations for the category of the given node, with the palette of
+// its status if the node belongs to a combined graph (see DiffGraph)
+func (t Theme) nodeDecorations(node Node) (Decorations, error) {
+	d, err := t.decorations(node.Category)
+	if err != nil {
+		return Decorations{}, err
+	}
+
+	if p, ok := diffPalettes[node.Diff]; ok {
+		d.palette = p
+	}
+
+	return d, nil
+}
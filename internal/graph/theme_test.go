@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupTheme(t *testing.T) {
+	assert.Equal(t, []string{"default", "monochrome"}, Themes())
+
+	for _, name := range Themes() {
+		theme, err := LookupTheme(name)
+		require.NoError(t, err)
+		assert.Len(t, theme, int(categoryCount), "inconsistent number of decorations in theme '%s'", name)
+	}
+
+	_, err := LookupTheme("not-a-theme")
+	require.ErrorContains(t, err, "default, monochrome")
+}
+
+func TestThemeDecorations(t *testing.T) {
+	// a nil theme falls back onto the default one
+	d, err := Theme(nil).decorations(CategoryDatabase)
+	require.NoError(t, err)
+	assert.Equal(t, categoryDecorations[CategoryDatabase], d)
+
+	d, err = MonochromeTheme.decorations(CategoryDatabase)
+	require.NoError(t, err)
+	assert.Equal(t, Cylinder, d.shape)
+	assert.Equal(t, White, d.palette.ColorFill)
+
+	_, err = MonochromeTheme.decorations(categoryCount)
+	require.Error(t, err)
+}
+
+func TestPrintTheme(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{Name: "my-database", Label: "my-database", Category: CategoryDatabase}},
+	}}, nil)
+	g.Theme = MonochromeTheme
+
+	var b strings.Builder
+	require.NoError(t, Print(&b, g))
+	assert.Contains(t, b.String(), `fillcolor = "white"`)
+	assert.NotContains(t, b.String(), `fillcolor = "/greens`)
+}
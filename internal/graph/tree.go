@@ -0,0 +1,249 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+)
+
+const (
+	treeBranch     = "├── "
+	treeLastBranch = "└── "
+	treeIndent     = "│   "
+	treeLastIndent = "    "
+
+	treeSeeAbove = "↑ see above"
+	treeCycle    = "↻ cycle"
+
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiBold  = "\x1b[1m"
+)
+
+// TreeOptions control the output of PrintTree
+type TreeOptions struct {
+	// Color enables ANSI colors from the category palettes (e.g. when printing to a terminal)
+	Color bool
+
+	// Reverse prints the dependents of each node rather than its dependencies
+	Reverse bool
+}
+
+var treeConditions = map[compose.Condition]string{
+	compose.ConditionServiceStarted:               "started",
+	compose.ConditionServiceHealthy:               "healthy",
+	compose.ConditionServiceCompletedSuccessfully: "completed",
+}
+
+// treeChild is a dependency (or a dependent, in reverse mode) along with its edge annotation
+type treeChild struct {
+	id         NodeID
+	annotation string
+}
+
+type tree struct {
+	options  TreeOptions
+	graph    Graph
+	group    int
+	expanded map[NodeID]bool
+}
+
+// PrintTree will print each node group as a tree of services with their dependencies & volumes;
+// subtrees shared by multiple services are printed once and referenced afterwards
+func PrintTree(w io.Writer, g Graph, options TreeOptions) error {
+	var b strings.Builder
+
+	for i, group := range g.Groups {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+
+		t := &tree{options: options, graph: g, group: i, expanded: make(map[NodeID]bool)}
+
+		b.WriteString(t.style(group.Label, ansiBold) + "\n")
+
+		roots := t.roots()
+
+		for i, id := range roots {
+			t.print(&b, treeChild{id: id}, "", i == len(roots)-1, nil)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write tree: %w", err)
+	}
+
+	return nil
+}
+
+// children returns the dependencies of the given node of the group, or its dependents in reverse
+// mode; the dependents are walked across all the groups, e.g. the services of other files mounting
+// a volume of this one
+func (t *tree) children(id NodeID) []treeChild {
+	var children []treeChild
+
+	if t.options.Reverse {
+		for _, e := range t.graph.Incoming(id) {
+			children = append(children, treeChild{id: e.From, annotation: treeAnnotation(e)})
+		}
+		return children
+	}
+
+	if id.Group != t.group {
+		return nil
+	}
+
+	// nodes are sorted, so are the edges & thus the children
+	for _, e := range t.graph.Outgoing(id) {
+		children = append(children, treeChild{id: e.To, annotation: treeAnnotation(e)})
+	}
+
+	return children
+}
+
+// treeAnnotation returns the condition (or the access mode of a mount) of the edge along with its diff marker
+func treeAnnotation(e Edge) string {
+	var annotation string
+
+	switch e.Kind {
+	case EdgeKindDependency:
+		annotation = "(" + dependencyNotes(treeConditions[e.Condition], e) + ")"
+
+	case EdgeKindVolumeMount:
+		annotation = "[" + readWrite(e.ReadOnly) + "]"
+	}
+
+	if marker := e.Diff.marker(); marker != "" {
+		annotation += " " + marker
+	}
+
+	return annotation
+}
+
+// roots returns the nodes without parents followed by any nodes only reachable through cycles
+func (t *tree) roots() []NodeID {
+	hasParent := make(map[NodeID]bool)
+
+	// the parents in other groups are left to their own trees
+	for _, node := range t.graph.Groups[t.group].Nodes {
+		for _, child := range t.children(NodeID{Group: t.group, Name: node.Name}) {
+			hasParent[child.id] = true
+		}
+	}
+
+	var roots []NodeID
+
+	reached := make(map[NodeID]bool)
+
+	var reach func(NodeID)
+	reach = func(id NodeID) {
+		if reached[id] {
+			return
+		}
+		reached[id] = true
+		for _, child := range t.children(id) {
+			reach(child.id)
+		}
+	}
+
+	for _, node := range t.graph.Groups[t.group].Nodes {
+		if id := (NodeID{Group: t.group, Name: node.Name}); !hasParent[id] {
+			roots = append(roots, id)
+			reach(id)
+		}
+	}
+
+	for _, node := range t.graph.Groups[t.group].Nodes {
+		if id := (NodeID{Group: t.group, Name: node.Name}); !reached[id] {
+			roots = append(roots, id)
+			reach(id)
+		}
+	}
+
+	return roots
+}
+
+// print prints the given node & (unless already printed) its subtree
+func (t *tree) print(w io.Writer, child treeChild, prefix string, last bool, path []NodeID) {
+	connector, indent := treeBranch, treeIndent
+	if last {
+		connector, indent = treeLastBranch, treeLastIndent
+	}
+
+	line := prefix + connector + t.label(child.id)
+
+	if child.annotation != "" {
+		line += " " + t.style(child.annotation, ansiDim)
+	}
+
+	children := t.children(child.id)
+
+	switch {
+	case slices.Contains(path, child.id):
+		fmt.Fprintln(w, line+" "+t.style(treeCycle, ansiDim))
+		return
+
+	case t.expanded[child.id] && len(children) > 0:
+		fmt.Fprintln(w, line+" "+t.style(treeSeeAbove, ansiDim))
+		return
+	}
+
+	fmt.Fprintln(w, line)
+
+	t.expanded[child.id] = true
+
+	for i, c := range children {
+		t.print(w, c, prefix+indent, i == len(children)-1, append(path, child.id))
+	}
+}
+
+// label returns the node label, prefixed with the label of its group when defined in another one &
+// colored with its category palette if requested
+func (t *tree) label(id NodeID) string {
+	node, ok := t.graph.Node(id)
+	if !ok {
+		return id.Name // not defined in any group
+	}
+
+	label := node.Label
+	if id.Group != t.group {
+		label = t.graph.Groups[id.Group].Label + ":" + label
+	}
+	if marker := node.Diff.marker(); marker != "" {
+		label += " " + marker
+	}
+
+	if !t.options.Color {
+		return label
+	}
+
+	d, err := t.graph.Theme.nodeDecorations(node)
+	if err != nil {
+		return label
+	}
+
+	return ansiColor(d.palette.ColorFill) + label + ansiReset
+}
+
+func (t *tree) style(s, code string) string {
+	if !t.options.Color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// ansiColor returns the 24-bit ANSI foreground color sequence for the given color
+func ansiColor(c Color) string {
+	h := strings.TrimPrefix(c.Hex(), "#")
+
+	rgb, err := strconv.ParseUint(h, 16, 32)
+	if err != nil || len(h) != 6 {
+		return ""
+	}
+
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16&0xff, rgb>>8&0xff, rgb&0xff)
+}
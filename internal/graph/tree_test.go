@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func treeTestGraph() Graph {
+	return NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "my-database", Label: "my-database", Category: CategoryDatabase},
+			{Name: "my-service", Label: "my-service", Category: CategoryService1},
+			{Name: "my-tool", Label: "my-tool", Category: CategoryTool},
+			{Name: "my-ui", Label: "my-ui", Category: CategoryUserInterface},
+			{Name: "my-volume", Label: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		mounts(0, "my-database", "my-volume", "/var/lib/data", false),
+		dependsOn(0, "my-service", "my-database", compose.ConditionServiceHealthy),
+		mounts(0, "my-service", "my-volume", "/tmp", true),
+		dependsOn(0, "my-tool", "my-service", compose.ConditionServiceCompletedSuccessfully),
+		dependsOn(0, "my-ui", "my-service", compose.ConditionServiceStarted),
+	})
+}
+
+func TestPrintTree(t *testing.T) {
+	var b strings.Builder
+
+	require.NoError(t, PrintTree(&b, treeTestGraph(), TreeOptions{}))
+
+	assert.Equal(t, `docker-compose.yaml
+├── my-tool
+│   └── my-service (completed)
+│       ├── my-database (healthy)
+│       │   └── my-volume [rw]
+│       └── my-volume [ro]
+└── my-ui
+    └── my-service (started) ↑ see above
+`, b.String())
+}
+
+func TestPrintTreeReverse(t *testing.T) {
+	var b strings.Builder
+
+	require.NoError(t, PrintTree(&b, treeTestGraph(), TreeOptions{Reverse: true}))
+
+	assert.Equal(t, `docker-compose.yaml
+└── my-volume
+    ├── my-database [rw]
+    │   └── my-service (healthy)
+    │       ├── my-tool (completed)
+    │       └── my-ui (started)
+    └── my-service [ro] ↑ see above
+`, b.String())
+}
+
+func TestPrintTreeReverseAcrossGroups(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "storage.yaml",
+		Nodes: []Node{
+			{Name: "backup", Label: "backup", Category: CategoryTool},
+			{Name: "shared", Label: "shared", Category: CategoryVolume},
+		},
+	}, {
+		Label: "app.yaml",
+		Nodes: []Node{
+			{Name: "backup", Label: "backup", Category: CategoryTool},
+			{Name: "web", Label: "web", Category: CategoryService1},
+		},
+	}}, []Edge{
+		mounts(0, "backup", "shared", "/backup", true),
+		dependsOn(1, "backup", "web", compose.ConditionServiceStarted),
+		{From: NodeID{Group: 1, Name: "web"}, To: NodeID{Group: 0, Name: "shared"}, Kind: EdgeKindVolumeMount, Target: "/data"},
+	})
+
+	var b strings.Builder
+
+	require.NoError(t, PrintTree(&b, g, TreeOptions{Reverse: true}))
+
+	// the dependents in other groups are walked too & labeled with their group
+	assert.Equal(t, `storage.yaml
+└── shared
+    ├── backup [ro]
+    └── app.yaml:web [rw]
+        └── app.yaml:backup (started)
+
+app.yaml
+└── web
+    └── backup (started)
+`, b.String())
+
+	b.Reset()
+
+	require.NoError(t, PrintTree(&b, g, TreeOptions{}))
+
+	assert.Equal(t, `storage.yaml
+└── backup
+    └── shared [ro]
+
+app.yaml
+└── backup
+    └── web (started)
+        └── storage.yaml:shared [rw]
+`, b.String())
+}
+
+func TestPrintTreeCycle(t *testing.T) {
+	var b strings.Builder
+
+	require.NoError(t, PrintTree(&b, NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{{Name: "a", Label: "a"}, {Name: "b", Label: "b"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "a", compose.ConditionServiceStarted),
+	}), TreeOptions{}))
+
+	assert.Equal(t, `docker-compose.yaml
+└── a
+    └── b (started)
+        └── a (started) ↻ cycle
+`, b.String())
+}
+
+func TestPrintTreeColor(t *testing.T) {
+	var b strings.Builder
+
+	require.NoError(t, PrintTree(&b, treeTestGraph(), TreeOptions{Color: true}))
+
+	assert.Contains(t, b.String(), "\x1b[38;2;136;65;157mmy-ui\x1b[0m")
+	assert.Contains(t, b.String(), "\x1b[2m(started)\x1b[0m")
+}
+
+func TestANSIColor(t *testing.T) {
+	assert.Equal(t, "\x1b[38;2;33;113;181m", ansiColor(Blue))
+	assert.Equal(t, "\x1b[38;2;255;255;255m", ansiColor(White))
+	assert.Equal(t, "", ansiColor(Color("unknown")))
+}
@@ -0,0 +1,114 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is an issue with a node found while validating the graph
+type Problem struct {
+	Node    NodeID
+	Message string
+}
+
+// Validate checks the graph for dependencies on undefined services, mounts of undeclared
+// volumes & dependency cycles (which would prevent the services from starting); compose skips
+// the optional dependencies on undefined services, so these are not reported
+func Validate(g Graph) []Problem {
+	var problems []Problem
+
+	for _, e := range g.Edges {
+		if _, ok := g.Node(e.To); ok {
+			continue
+		}
+
+		switch e.Kind {
+		case EdgeKindDependency:
+			if !e.Required {
+				continue
+			}
+
+			problems = append(problems, Problem{
+				Node:    e.From,
+				Message: fmt.Sprintf("depends on undefined service '%s'", e.To.Name),
+			})
+
+		case EdgeKindVolumeMount:
+			problems = append(problems, Problem{
+				Node:    e.From,
+				Message: fmt.Sprintf("mounts undeclared volume '%s'", e.To.Name),
+			})
+		}
+	}
+
+	for _, cycle := range dependencyCycles(g) {
+		names := make([]string, 0, len(cycle)+1)
+		for _, id := range cycle {
+			names = append(names, id.Name)
+		}
+		names = append(names, cycle[0].Name)
+
+		problems = append(problems, Problem{
+			Node:    cycle[0],
+			Message: "dependency cycle: " + strings.Join(names, " -> "),
+		})
+	}
+
+	return problems
+}
+
+// dependencyCycles returns the cycles found by a depth-first search of the dependency edges,
+// one for each edge leading back onto the current path
+func dependencyCycles(g Graph) [][]NodeID {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		cycles [][]NodeID
+		path   []NodeID
+		state  = make(map[NodeID]int)
+	)
+
+	var visit func(id NodeID)
+	visit = func(id NodeID) {
+		state[id] = visiting
+		path = append(path, id)
+
+		for _, e := range g.Outgoing(id) {
+			if e.Kind != EdgeKindDependency {
+				continue
+			}
+			if _, ok := g.Node(e.To); !ok {
+				continue
+			}
+
+			switch state[e.To] {
+			case unvisited:
+				visit(e.To)
+			case visiting:
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == e.To {
+						cycles = append(cycles, append([]NodeID(nil), path[i:]...))
+						break
+					}
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[id] = visited
+	}
+
+	for gi, group := range g.Groups {
+		for _, node := range group.Nodes {
+			if id := (NodeID{Group: gi, Name: node.Name}); state[id] == unvisited {
+				visit(id)
+			}
+		}
+	}
+
+	return cycles
+}
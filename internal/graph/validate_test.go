@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Label: "docker-compose.yaml",
+		Nodes: []Node{
+			{Name: "a", Category: CategoryService1},
+			{Name: "b", Category: CategoryService1},
+			{Name: "c", Category: CategoryService1},
+			{Name: "d", Category: CategoryService1},
+			{Name: "my-volume", Category: CategoryVolume},
+		},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+		dependsOn(0, "b", "c", compose.ConditionServiceStarted),
+		dependsOn(0, "c", "a", compose.ConditionServiceHealthy),
+		dependsOn(0, "d", "missing", compose.ConditionServiceStarted),
+		mounts(0, "d", "my-volume", "/data", false),
+		mounts(0, "d", "undeclared", "/other", false),
+	})
+
+	assert.Equal(t, []Problem{
+		{Node: NodeID{Group: 0, Name: "d"}, Message: "depends on undefined service 'missing'"},
+		{Node: NodeID{Group: 0, Name: "d"}, Message: "mounts undeclared volume 'undeclared'"},
+		{Node: NodeID{Group: 0, Name: "a"}, Message: "dependency cycle: a -> b -> c -> a"},
+	}, Validate(g))
+}
+
+func TestValidateValid(t *testing.T) {
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{{Name: "a"}, {Name: "b"}},
+	}}, []Edge{
+		dependsOn(0, "a", "b", compose.ConditionServiceStarted),
+	})
+
+	assert.Empty(t, Validate(g))
+}
+
+func TestValidateOptional(t *testing.T) {
+	optional := dependsOn(0, "a", "missing", compose.ConditionServiceStarted)
+	optional.Required = false
+
+	g := NewGraph([]NodeGroup{{
+		Nodes: []Node{{Name: "a"}},
+	}}, []Edge{optional})
+
+	assert.Empty(t, Validate(g))
+}
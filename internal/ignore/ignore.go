@@ -0,0 +1,155 @@
+// Package ignore implements .gitignore-style path exclusion
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Matcher holds the exclusion rules, each scoped to the directory it was added for
+type Matcher struct {
+	rules []rule
+}
+
+type rule struct {
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Add adds the patterns (in .gitignore syntax) applying to the paths below the given slash-separated
+// base directory; later patterns take precedence over earlier ones
+func (m *Matcher) Add(base string, patterns ...string) error {
+	for _, p := range patterns {
+		p = strings.TrimRight(p, " ")
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+
+		r := rule{base: path.Clean(base)}
+
+		if strings.HasPrefix(p, "!") {
+			r.negate = true
+			p = p[1:]
+		}
+
+		if strings.HasSuffix(p, "/") {
+			r.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+
+		re, err := compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", p, err)
+		}
+		r.pattern = re
+
+		m.rules = append(m.rules, r)
+	}
+
+	return nil
+}
+
+// AddFile adds the patterns read from a .gitignore file located in the given base directory
+func (m *Matcher) AddFile(base string, r io.Reader) error {
+	var patterns []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read patterns: %w", err)
+	}
+
+	return m.Add(base, patterns...)
+}
+
+// Match reports whether the slash-separated path is excluded; the last matching rule wins
+func (m *Matcher) Match(name string, isDir bool) bool {
+	name = path.Clean(name)
+	excluded := false
+
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		rel := name
+		if r.base != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(name, r.base+"/"); !ok {
+				continue
+			}
+		}
+
+		if r.pattern.MatchString(rel) {
+			excluded = !r.negate
+		}
+	}
+
+	return excluded
+}
+
+// compile translates the pattern into a regular expression matching the relative paths: patterns
+// without a slash (other than a trailing one) match at any depth, the others are anchored
+func compile(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+
+		case '?':
+			b.WriteString("[^/]")
+
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
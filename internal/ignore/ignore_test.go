@@ -0,0 +1,52 @@
+package ignore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	var m Matcher
+
+	require.NoError(t, m.AddFile(".", strings.NewReader(`
+# comment
+node_modules/
+*.bak
+/build
+docs/**/drafts
+legacy-*
+!legacy-keep
+`)))
+	require.NoError(t, m.Add("services", "internal/"))
+
+	for _, tc := range []struct {
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		{"node_modules", true, true},
+		{"services/api/node_modules", true, true},
+		{"services/api/node_modules", false, false}, // directory only
+		{"compose.yaml.bak", false, true},
+		{"services/api/compose.yaml.bak", false, true},
+		{"build", true, true},
+		{"services/build", true, false}, // anchored
+		{"docs/drafts", true, true},
+		{"docs/a/b/drafts", true, true},
+		{"legacy-api", true, true},
+		{"services/legacy-keep", true, false}, // negated
+		{"services/internal", true, true},
+		{"internal", true, false}, // scoped to services
+		{"services/api", true, false},
+	} {
+		assert.Equal(t, tc.excluded, m.Match(tc.path, tc.isDir), tc.path)
+	}
+}
+
+func TestAddInvalid(t *testing.T) {
+	var m Matcher
+	require.ErrorContains(t, m.Add(".", "[abc"), "unterminated")
+}
@@ -0,0 +1,180 @@
+// Package textdiff compares texts line by line & formats the differences as a unified diff
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines surrounding the changes of a hunk
+const context = 3
+
+// maxCells bounds the size of the table computing the longest common subsequence of the changed
+// lines; larger changes are reported as a replacement of all the lines in between
+const maxCells = 1 << 22
+
+type op struct {
+	kind byte // ' ' (unchanged), '-' (removed) or '+' (added)
+	line string
+}
+
+// Unified returns the differences between the two texts in the unified diff format (as output by
+// 'diff -u'), the texts being labelled with the given names; it returns an empty string if the
+// texts are identical
+func Unified(beforeName, afterName string, before, after []byte) string {
+	ops := diff(lines(string(before)), lines(string(after)))
+
+	var b strings.Builder
+
+	// the number of lines of each text preceding each operation
+	beforeLines, afterLines := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, o := range ops {
+		beforeLines[i+1], afterLines[i+1] = beforeLines[i], afterLines[i]
+		if o.kind != '+' {
+			beforeLines[i+1]++
+		}
+		if o.kind != '-' {
+			afterLines[i+1]++
+		}
+	}
+
+	for start := 0; start < len(ops); {
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// the hunk extends over the changes separated by no more unchanged lines than the context
+		// on both sides
+		last := first
+		for i := first; i < len(ops) && i-last <= 2*context+1; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+
+		from, to := max(start, first-context), min(len(ops), last+1+context)
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", beforeName, afterName)
+		}
+
+		fmt.Fprintf(
+			&b,
+			"@@ -%s +%s @@\n",
+			hunkRange(beforeLines[from], beforeLines[to]-beforeLines[from]),
+			hunkRange(afterLines[from], afterLines[to]-afterLines[from]),
+		)
+
+		for _, o := range ops[from:to] {
+			b.WriteByte(o.kind)
+			b.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = to
+	}
+
+	return b.String()
+}
+
+// hunkRange formats the first line & the number of lines of a hunk; an empty range starts at
+// the line preceding it
+func hunkRange(preceding, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", preceding)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", preceding+1)
+	}
+	return fmt.Sprintf("%d,%d", preceding+1, count)
+}
+
+// lines splits the text into lines, each keeping its line feed (the last one may lack it)
+func lines(text string) []string {
+	l := strings.SplitAfter(text, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+// diff returns the operations turning the lines before into the lines after: the common prefix &
+// suffix are kept, the lines in between are compared by their longest common subsequence
+func diff(before, after []string) []op {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(before)+len(after)-prefix-suffix)
+
+	for _, line := range before[:prefix] {
+		ops = append(ops, op{kind: ' ', line: line})
+	}
+
+	ops = append(ops, middle(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+
+	for _, line := range before[len(before)-suffix:] {
+		ops = append(ops, op{kind: ' ', line: line})
+	}
+
+	return ops
+}
+
+func middle(before, after []string) []op {
+	var ops []op
+
+	n, m := len(before), len(after)
+
+	if n*m > maxCells {
+		for _, line := range before {
+			ops = append(ops, op{kind: '-', line: line})
+		}
+		for _, line := range after {
+			ops = append(ops, op{kind: '+', line: line})
+		}
+		return ops
+	}
+
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of before[i:] & after[j:]
+	lcs := make([]int32, (n+1)*(m+1))
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			ops = append(ops, op{kind: ' ', line: before[i]})
+			i, j = i+1, j+1
+		case j == m || (i < n && lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]):
+			ops = append(ops, op{kind: '-', line: before[i]})
+			i++
+		default:
+			ops = append(ops, op{kind: '+', line: after[j]})
+			j++
+		}
+	}
+
+	return ops
+}
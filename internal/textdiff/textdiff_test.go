@@ -0,0 +1,75 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	numbered := func(lines ...string) []byte {
+		return []byte(strings.Join(lines, "\n") + "\n")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		before   []byte
+		after    []byte
+		expected string
+	}{
+		{
+			name:     "identical",
+			before:   numbered("a", "b"),
+			after:    numbered("a", "b"),
+			expected: "",
+		},
+		{
+			name:   "modified line",
+			before: numbered("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			after:  numbered("1", "2", "3", "4", "five", "6", "7", "8", "9"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "separate hunks",
+			before: numbered("1", "2", "3", "4", "5", "6", "7", "8", "9", "10"),
+			after:  numbered("one", "2", "3", "4", "5", "6", "7", "8", "9", "ten"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:   "merged hunks",
+			before: numbered("1", "2", "3", "4", "5", "6", "7", "8"),
+			after:  numbered("one", "2", "3", "4", "5", "6", "7", "eight"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		{
+			name:   "insertion & deletion",
+			before: numbered("a", "b", "c"),
+			after:  numbered("a", "x", "c", "d"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -1,3 +1,4 @@\n a\n-b\n+x\n c\n+d\n",
+		},
+		{
+			name:   "empty before",
+			before: nil,
+			after:  numbered("a"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:   "missing line feed",
+			before: []byte("a\nb"),
+			after:  []byte("a\nb\n"),
+			expected: "--- before\n+++ after\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Unified("before", "after", tc.before, tc.after))
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+var lintCommand = command{
+	name:    "lint",
+	args:    "<compose-file|dir>...",
+	summary: "check the services & volumes against opinionated rules (severities are set in the config's lint section)",
+	formats: []string{"text", "json", "sarif"},
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		return func(c *cli, args []string) error {
+			if len(args) == 0 {
+				return usageError("no compose files given")
+			}
+
+			severities, err := c.lintSeverities()
+			if err != nil {
+				return err
+			}
+
+			g, err := c.load(args)
+			if err != nil {
+				return err
+			}
+
+			findings := composegraph.Lint(g, severities)
+
+			err = c.write(func(w io.Writer) error {
+				switch c.format {
+				case "json":
+					return printLintJSON(w, g, findings)
+				case "sarif":
+					return printLintSARIF(w, findings)
+				}
+				return printLintText(w, g, findings)
+			})
+			if err != nil {
+				return err
+			}
+
+			if n := countErrors(findings); n != 0 {
+				return failure(fmt.Errorf("found %d error(s)", n))
+			}
+
+			return nil
+		}
+	},
+}
+
+// lintSeverities returns the severities of the rules configured in the config file
+func (c *cli) lintSeverities() (map[string]composegraph.Severity, error) {
+	rules := composegraph.LintRules()
+
+	severities := make(map[string]composegraph.Severity, len(c.config.Lint.Rules))
+
+	// sorted to report the same error on each run
+	for _, id := range slices.Sorted(maps.Keys(c.config.Lint.Rules)) {
+		if !slices.ContainsFunc(rules, func(r composegraph.LintRule) bool { return r.ID == id }) {
+			var available []string
+			for _, r := range rules {
+				available = append(available, r.ID)
+			}
+			return nil, usageError("unknown lint rule '%s' in config (available: %s)", id, strings.Join(available, ", "))
+		}
+
+		severity, ok := composegraph.ParseSeverity(c.config.Lint.Rules[id])
+		if !ok {
+			return nil, usageError("invalid severity '%s' of lint rule '%s' in config (available: off, note, warning, error)", c.config.Lint.Rules[id], id)
+		}
+
+		severities[id] = severity
+	}
+
+	return severities, nil
+}
+
+func countErrors(findings []composegraph.Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == composegraph.SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+func printLintText(w io.Writer, g composegraph.Graph, findings []composegraph.Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s: %s: %s: %s [%s]\n", g.Groups[f.Node.Group].Label, f.Node.Name, f.Severity, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type lintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+func printLintJSON(w io.Writer, g composegraph.Graph, findings []composegraph.Finding) error {
+	out := make([]lintFinding, 0, len(findings))
+
+	for _, f := range findings {
+		out = append(out, lintFinding{
+			Rule:     f.Rule,
+			Severity: f.Severity.String(),
+			Group:    g.Groups[f.Node.Group].Label,
+			Name:     f.Node.Name,
+			Message:  f.Message,
+			File:     f.Path,
+			Line:     f.Line,
+		})
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(out)
+}
+
+// the subset of the SARIF 2.1.0 format read by the code scanning tools
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// printLintSARIF prints the findings as a SARIF log; the severities map to the SARIF levels of
+// the same names ('off' rules are listed with level 'none')
+func printLintSARIF(w io.Writer, findings []composegraph.Finding) error {
+	rules := composegraph.LintRules()
+
+	driver := sarifDriver{
+		Name:           program,
+		Version:        versionString(),
+		InformationURI: "https://github.com/averche/docker-compose-graph",
+	}
+
+	for _, r := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: slices.IndexFunc(rules, func(r composegraph.LintRule) bool { return r.ID == f.Rule }),
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{Text: fmt.Sprintf("'%s' %s", f.Node.Name, f.Message)},
+		}
+
+		if f.Path != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(f.Path)},
+				},
+			}
+			if f.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+
+		results = append(results, result)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+func sarifLevel(s composegraph.Severity) string {
+	if s == composegraph.SeverityOff {
+		return "none"
+	}
+	return s.String()
+}
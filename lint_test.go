@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintCompose = `services:
+  proxy:
+    image: traefik:v3
+    labels:
+      graph.node.category: proxy
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock:ro
+  api:
+    image: example/api
+    labels:
+      graph.node.category: service1
+      graph.lint.ignore: latest-image-tag
+    depends_on: [db]
+  db:
+    image: postgres:17
+    labels:
+      graph.node.category: database
+volumes:
+  unused: {}
+`
+
+func TestRunLint(t *testing.T) {
+	input := writeFile(t, "compose.yaml", lintCompose)
+
+	code, stdout, stderr := runCLI(t, "lint", input)
+	assert.Equal(t, exitFailure, code)
+	assert.Equal(t, ""+
+		"compose.yaml: api: warning: depends on database 'db' with condition service_started rather than service_healthy [database-dependency-not-healthy]\n"+
+		"compose.yaml: proxy: error: bind mounts the docker socket '/var/run/docker.sock' [docker-socket-mount]\n"+
+		"compose.yaml: unused: warning: is declared but not mounted by any service [unused-volume]\n",
+		stdout)
+	assert.Contains(t, stderr, "found 1 error(s)")
+
+	// the severities are overridden by the config
+	config := writeFile(t, "config.yaml", "lint:\n  rules:\n    docker-socket-mount: warning\n    unused-volume: off\n")
+
+	code, stdout, stderr = runCLI(t, "lint", "-config", config, "-format", "json", input)
+	require.Equal(t, exitOK, code, stderr)
+
+	var findings []lintFinding
+	require.NoError(t, json.Unmarshal([]byte(stdout), &findings))
+	require.Len(t, findings, 2)
+	assert.Equal(t, lintFinding{
+		Rule:     "docker-socket-mount",
+		Severity: "warning",
+		Group:    "compose.yaml",
+		Name:     "proxy",
+		Message:  "bind mounts the docker socket '/var/run/docker.sock'",
+		File:     input,
+		Line:     2,
+	}, findings[1])
+
+	code, _, stderr = runCLI(t, "lint", "-config", writeFile(t, "config.yaml", "lint:\n  rules:\n    no-such-rule: error\n"), input)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown lint rule 'no-such-rule'")
+
+	code, _, stderr = runCLI(t, "lint", "-config", writeFile(t, "config.yaml", "lint:\n  rules:\n    unused-volume: fatal\n"), input)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "invalid severity 'fatal'")
+}
+
+func TestRunLintSARIF(t *testing.T) {
+	input := writeFile(t, "compose.yaml", lintCompose)
+
+	code, stdout, _ := runCLI(t, "lint", "-format", "sarif", input)
+	assert.Equal(t, exitFailure, code)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(stdout), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, program, run.Tool.Driver.Name)
+	require.Len(t, run.Results, 3)
+
+	result := run.Results[1]
+	assert.Equal(t, "docker-socket-mount", result.RuleID)
+	assert.Equal(t, "docker-socket-mount", run.Tool.Driver.Rules[result.RuleIndex].ID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "'proxy' bind mounts the docker socket '/var/run/docker.sock'", result.Message.Text)
+	require.Len(t, result.Locations, 1)
+	require.NotNil(t, result.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, 2, result.Locations[0].PhysicalLocation.Region.StartLine)
+
+	// the volumes are located by the file declaring them
+	assert.Nil(t, run.Results[2].Locations[0].PhysicalLocation.Region)
+}
@@ -1,30 +1,467 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
 
-	"github.com/averche/docker-compose-graph/internal/compose"
-	"github.com/averche/docker-compose-graph/internal/graph"
+	"github.com/averche/docker-compose-graph/internal/config"
+	"github.com/averche/docker-compose-graph/internal/textdiff"
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
 )
 
+const program = "docker-compose-graph"
+
+// exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1 // validation failed (or differences found by diff or -check)
+	exitUsage   = 2 // invalid command, flags or arguments
+	exitParse   = 3 // the compose (or config) files could not be parsed
+	exitError   = 4 // the output could not be rendered or written
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version string
+
+// cliError carries the exit code of a failed command; a nil err exits without a message
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+func usageError(format string, args ...any) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func parseError(err error) error {
+	return &cliError{code: exitParse, err: err}
+}
+
+func failure(err error) error {
+	return &cliError{code: exitFailure, err: err}
+}
+
+// command is a subcommand; setup registers its own flags & returns the function running it
+type command struct {
+	name    string
+	args    string
+	summary string
+	formats []string
+	setup   func(fs *flag.FlagSet) func(c *cli, args []string) error
+
+	// inPlace is set for the commands rewriting their input files rather than writing to -o
+	inPlace bool
+}
+
+var commands = []command{
+	graphCommand,
+	validateCommand,
+	orderCommand,
+	diffCommand,
+	statsCommand,
+	lintCommand,
+	impactCommand,
+	embedCommand,
+	serveCommand,
+}
+
+// cli holds the flags shared by all the commands
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	output     string
+	format     string
+	theme      string
+	configPath string
+	recursive  bool
+	ignore     stringList
+	label      string
+	check      bool
+
+	// legend is the placement of the legend & focus the patterns of the nodes to focus on, only set
+	// by the graph command
+	legend string
+	focus  stringList
+
+	config config.Config
+
+	// stdinRead is set once stdin ('-') is consumed by a command
+	stdinRead bool
+
+	// stale is set once an output file is found to differ from the rendering in check mode
+	stale bool
+}
+
 func main() {
-	var groups []graph.NodeGroup
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOK
+	case "version", "-version", "--version":
+		fmt.Fprintf(stdout, "%s %s\n", program, versionString())
+		return exitOK
+	}
+
+	i := slices.IndexFunc(commands, func(cmd command) bool { return cmd.name == args[0] })
+
+	switch {
+	case i != -1:
+		args = args[1:]
+	case strings.HasPrefix(args[0], "-") || exists(args[0]):
+		// the graph command is implied for backwards compatibility (e.g. "docker-compose-graph docker-compose.yaml")
+		i = 0
+	default:
+		fmt.Fprintf(stderr, "unknown command '%s'\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
 
-	for _, path := range os.Args[1:] {
-		// parse each file & construct graph nodes
-		f, err := compose.ParseFile(path)
+	cmd := commands[i]
+	c := cli{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	fs, runner := c.flags(cmd)
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage // the flag set has already printed the error & the usage
+	}
+
+	err := c.configure(cmd)
+	if err == nil {
+		err = runner(&c, fs.Args())
+	}
+	if err == nil && c.stale {
+		err = failure(nil)
+	}
+
+	var e *cliError
+
+	switch {
+	case err == nil:
+		return exitOK
+
+	case errors.As(err, &e) && e.code == exitUsage:
+		fmt.Fprintf(stderr, "%v\n\n", e.err)
+		printCommandUsage(fs, cmd)
+		return exitUsage
+
+	case errors.As(err, &e):
+		if e.err != nil {
+			fmt.Fprintf(stderr, "Error :: %v\n", e.err)
+		}
+		return e.code
+	}
+
+	fmt.Fprintf(stderr, "Error :: %v\n", err)
+	return exitError
+}
+
+// flags registers the shared flags & the flags of the command on a new flag set, returning it
+// along with the function running the command
+func (c *cli) flags(cmd command) (*flag.FlagSet, func(c *cli, args []string) error) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() { printCommandUsage(fs, cmd) }
+
+	fs.StringVar(&c.output, "o", "", "write the output to the given file instead of stdout")
+	fs.StringVar(&c.output, "output", "", "write the output to the given file instead of stdout")
+	fs.StringVar(&c.format, "format", "", "output format: "+strings.Join(cmd.formats, ", "))
+	fs.StringVar(&c.theme, "theme", "", "node color theme: "+strings.Join(composegraph.Themes(), ", "))
+	fs.StringVar(&c.configPath, "config", "", "yaml file with the defaults for -format & -theme, the service filters & category overrides")
+	fs.BoolVar(&c.recursive, "recursive", false, "search the directories given as inputs recursively for compose files")
+	fs.Var(&c.ignore, "ignore", "`pattern` (.gitignore syntax) of the paths to skip when searching directories; may be repeated")
+	fs.StringVar(&c.label, "label", "", "cluster label of the compose file read from stdin ('-') (default: its project name or \"stdin\")")
+	fs.BoolVar(&c.check, "check", false, "compare the rendering with the existing output file & print the differences instead of writing it")
+
+	return fs, cmd.setup(fs)
+}
+
+// configure loads the config file (if any) & validates the shared flags against it
+func (c *cli) configure(cmd command) error {
+	if c.configPath != "" {
+		cfg, err := config.Load(c.configPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error :: could not parse '%s': %v\n", path, err)
-			os.Exit(1)
+			return parseError(err)
+		}
+		if err := checkConfig(cfg); err != nil {
+			return parseError(fmt.Errorf("invalid config '%s': %w", c.configPath, err))
+		}
+		c.config = cfg
+	}
+
+	switch {
+	case cmd.inPlace && c.output != "":
+		return usageError("the %s command rewrites its input files & has no output file (-o)", cmd.name)
+	case c.check && c.output == "" && !cmd.inPlace:
+		return usageError("-check requires an output file (-o)")
+	}
+
+	if c.theme == "" {
+		c.theme = c.config.Theme
+	}
+
+	// the config format only applies to the graph command, the other commands print text
+	if c.format == "" && cmd.name == graphCommand.name {
+		c.format = c.config.Format
+	}
+
+	if c.format == "" {
+		c.format = cmd.formats[0]
+		if f, ok := composegraph.FormatFromPath(c.output); ok && slices.Contains(cmd.formats, f) {
+			c.format = f
 		}
+	}
 
-		groups = append(groups, graph.NodeGroup{
-			Label: filepath.Base(path),
-			Nodes: graph.NodesFromFile(f),
-		})
+	if !slices.Contains(cmd.formats, c.format) {
+		return usageError("unsupported format '%s' (available: %s)", c.format, strings.Join(cmd.formats, ", "))
 	}
 
-	graph.Print(os.Stdout, groups)
+	return nil
+}
+
+// checkConfig validates the values of the config up front, so that the errors building & rendering
+// the graph are left to the flags & arguments
+func checkConfig(cfg config.Config) error {
+	if cfg.Format != "" && !slices.Contains(graphCommand.formats, cfg.Format) {
+		return fmt.Errorf("unsupported format '%s' (available: %s)", cfg.Format, strings.Join(graphCommand.formats, ", "))
+	}
+
+	// an empty project checks the theme, the legend, the categories & the patterns
+	var project composegraph.Project
+	if _, err := project.Graph(composegraph.Options{
+		Include:    cfg.Include,
+		Exclude:    cfg.Exclude,
+		Categories: cfg.Categories,
+		Theme:      cfg.Theme,
+		Legend:     cfg.Legend,
+	}); err != nil {
+		return err
+	}
+
+	if err := composegraph.DOTLayout(cfg.Layout).Validate(); err != nil {
+		return err
+	}
+
+	return composegraph.Annotations(cfg.Annotations).Validate()
+}
+
+// load parses the given compose files (or the ones found in the given directories, or stdin
+// for "-") into a graph using the shared flags & the config
+func (c *cli) load(paths []string) (composegraph.Graph, error) {
+	project, err := c.loadProject(paths)
+	if err != nil {
+		return composegraph.Graph{}, err
+	}
+
+	return c.graph(project)
+}
+
+// loadProject parses the given compose files (or the ones found in the given directories, or stdin
+// for "-") into a project
+func (c *cli) loadProject(paths []string) (composegraph.Project, error) {
+	var project composegraph.Project
+
+	for _, path := range paths {
+		if path == "-" {
+			if c.stdinRead {
+				return composegraph.Project{}, usageError("stdin ('-') can only be read once")
+			}
+			c.stdinRead = true
+
+			if err := project.AddReader(c.label, c.stdin); err != nil {
+				return composegraph.Project{}, parseError(err)
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return composegraph.Project{}, parseError(fmt.Errorf("could not parse '%s': %w", path, err))
+		}
+
+		if info.IsDir() {
+			err = project.AddDir(path, c.dirOptions())
+		} else {
+			err = project.AddFile(path)
+		}
+		if err != nil {
+			return composegraph.Project{}, parseError(err)
+		}
+	}
+
+	return project, nil
+}
+
+// dirOptions returns the options of the discovery of the compose files within the directories
+func (c *cli) dirOptions() composegraph.DirOptions {
+	return composegraph.DirOptions{
+		Recursive: c.recursive,
+		Ignore:    append(slices.Clone(c.config.Ignore), c.ignore...),
+	}
+}
+
+// graph builds the graph of the project using the shared flags & the config
+func (c *cli) graph(project composegraph.Project) (composegraph.Graph, error) {
+	g, err := project.Graph(c.options())
+	if err != nil {
+		// the values of the config are checked by configure, the remaining errors are the flags'
+		return composegraph.Graph{}, usageError("%v", err)
+	}
+
+	return g, nil
+}
+
+// options returns the options building the graph from the shared flags & the config
+func (c *cli) options() composegraph.Options {
+	return composegraph.Options{
+		Include:    c.config.Include,
+		Exclude:    c.config.Exclude,
+		Focus:      c.focus,
+		Categories: c.config.Categories,
+		Theme:      c.theme,
+		Legend:     c.legend,
+	}
+}
+
+// write renders into the output file or stdout
+func (c *cli) write(render func(w io.Writer) error) error {
+	return c.writeTo(c.output, render)
+}
+
+// writeTo renders into the file at the given path or stdout if empty; the file is only written
+// once the rendering succeeds so that a failure does not leave a truncated file behind. In check
+// mode, the file is compared with the rendering instead & the differences are printed to stdout
+func (c *cli) writeTo(path string, render func(w io.Writer) error) error {
+	if path == "" {
+		if err := render(c.stdout); err != nil {
+			return fmt.Errorf("could not render: %w", err)
+		}
+		return nil
+	}
+
+	var b bytes.Buffer
+
+	if err := render(&b); err != nil {
+		return fmt.Errorf("could not render: %w", err)
+	}
+
+	if c.check {
+		return c.compare(path, b.Bytes())
+	}
+
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// compare prints the unified diff between the file at the given path & its expected contents,
+// marking the output as stale if they differ; a missing file is compared as empty
+func (c *cli) compare(path string, expected []byte) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not read '%s': %w", path, err)
+	}
+
+	if d := textdiff.Unified(path, path+" (rendered)", existing, expected); d != "" {
+		fmt.Fprint(c.stdout, d)
+		c.stale = true
+	}
+
+	return nil
+}
+
+// color reports whether the output is an interactive terminal which should be colored
+func (c *cli) color() bool {
+	f, ok := c.stdout.(*os.File)
+	return ok && c.output == "" && isTerminal(f) && os.Getenv("NO_COLOR") == ""
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] <compose-file|dir>...\n\n", program)
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nThe graph command is implied if omitted. Run '%s <command> -help' for the flags of each command.\n", program)
+	fmt.Fprintf(w, "\nExit codes:\n")
+	fmt.Fprintf(w, "  %d  success\n", exitOK)
+	fmt.Fprintf(w, "  %d  validation failed or differences found\n", exitFailure)
+	fmt.Fprintf(w, "  %d  usage error\n", exitUsage)
+	fmt.Fprintf(w, "  %d  the compose or config files could not be parsed\n", exitParse)
+	fmt.Fprintf(w, "  %d  the output could not be rendered or written\n", exitError)
+}
+
+func printCommandUsage(fs *flag.FlagSet, cmd command) {
+	fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n\n%s\n\nFlags:\n", program, cmd.name, cmd.args, cmd.summary)
+	fs.PrintDefaults()
+}
+
+// stringList is a flag which may be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func versionString() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isTerminal reports whether the given file is a character device (e.g. an interactive terminal)
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
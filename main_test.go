@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runCLIWithStdin(t, "", args...)
+}
+
+func runCLIWithStdin(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr strings.Builder
+
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := runCLI(t)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Usage:")
+
+	code, stdout, _ := runCLI(t, "--help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "validate")
+
+	code, stdout, _ = runCLI(t, "--version")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "docker-compose-graph dev\n", stdout)
+
+	code, _, stderr = runCLI(t, "not-a-command")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown command 'not-a-command'")
+
+	// usage errors print the help rather than an error line
+	for _, args := range [][]string{
+		{"graph"},
+		{"graph", "-not-a-flag", "examples/simple.yaml"},
+		{"graph", "-format", "not-a-format", "examples/simple.yaml"},
+		{"graph", "-theme", "not-a-theme", "examples/simple.yaml"},
+		{"graph", "-legend", "not-a-legend", "examples/simple.yaml"},
+		{"graph", "-rankdir", "up", "examples/simple.yaml"},
+		{"graph", "-format", "tree", "-legend", "none", "examples/simple.yaml"},
+		{"graph", "-legend", "only", "-legend-output", "legend.dot", "examples/simple.yaml"},
+		{"stats", "-format", "svg", "examples/simple.yaml"},
+		{"diff", "examples/simple.yaml"},
+	} {
+		code, _, stderr = runCLI(t, args...)
+		assert.Equal(t, exitUsage, code, args)
+		assert.Contains(t, stderr, "Usage: docker-compose-graph "+args[0], args)
+		assert.NotContains(t, stderr, "Error ::", args)
+	}
+}
+
+func TestRunGraph(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "graph", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.True(t, strings.HasPrefix(stdout, "digraph compose {"))
+
+	// the graph command is implied
+	code, implied, _ := runCLI(t, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, stdout, implied)
+
+	// the format is inferred from the output file extension
+	output := filepath.Join(t.TempDir(), "graph.mmd")
+
+	code, stdout, stderr = runCLI(t, "graph", "-o", output, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	b, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "flowchart TB"))
+
+	code, stdout, stderr = runCLI(t, "graph", "-edge-labels", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `label="service_healthy"`)
+
+	code, stdout, stderr = runCLI(t, "graph", "-format", "tree", "-focus", "db", "-focus", "c*", writeFile(t, "compose.yaml", "services:\n  web:\n    depends_on: [db]\n  db: {}\n  cache: {}\n  tool: {}\n"))
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "compose.yaml\n├── cache\n└── web\n    └── db (started)\n", stdout)
+}
+
+func TestRunCheck(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "graph.dot")
+
+	// a missing output is stale & is not written
+	code, stdout, stderr := runCLI(t, "graph", "-check", "-o", output, "examples/simple.yaml")
+	assert.Equal(t, exitFailure, code, stderr)
+	assert.Contains(t, stdout, "--- "+output+"\n+++ "+output+" (rendered)\n@@ -0,0 +1,")
+	assert.NoFileExists(t, output)
+
+	code, _, stderr = runCLI(t, "graph", "-o", output, "examples/simple.yaml")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, stderr = runCLI(t, "graph", "-check", "-o", output, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	code, stdout, stderr = runCLI(t, "graph", "-check", "-o", output, "-rankdir", "LR", "examples/simple.yaml")
+	assert.Equal(t, exitFailure, code, stderr)
+	assert.Contains(t, stdout, "\n-  graph [fontname = \"arial\"];\n+  graph [fontname = \"arial\" rankdir = \"LR\"];\n")
+
+	b, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "rankdir")
+
+	code, _, stderr = runCLI(t, "graph", "-check", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "-check requires an output file")
+}
+
+func TestRunLegend(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "graph", "-legend", "none", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stdout, `label = "Legend"`)
+
+	code, stdout, stderr = runCLI(t, "graph", "-legend", "only", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `label = "Legend"`)
+	assert.NotContains(t, stdout, `label = "simple.yaml"`)
+
+	// the legend is written separately in the output format
+	legend := filepath.Join(t.TempDir(), "legend.svg")
+
+	code, stdout, stderr = runCLI(t, "graph", "-format", "svg", "-legend-output", legend, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stdout, `class="legend-node"`)
+
+	b, err := os.ReadFile(legend)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `class="legend-node"`)
+	assert.NotContains(t, string(b), `class="node"`)
+
+	config := writeFile(t, "config.yaml", "legend: none\n")
+
+	code, stdout, stderr = runCLI(t, "graph", "--config", config, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stdout, `label = "Legend"`)
+
+	// the legend of the config is ignored by the formats without a legend, unlike the flags
+	for _, format := range []string{"tree", "mermaid", "json", "markdown"} {
+		code, _, stderr = runCLI(t, "graph", "--config", config, "-format", format, "examples/simple.yaml")
+		assert.Equal(t, exitOK, code, format+": "+stderr)
+	}
+
+	code, _, stderr = runCLI(t, "graph", "--config", config, "-format", "tree", "-legend-output", "legend.txt", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "the tree format has no legend")
+
+	// the flags take precedence over the config
+	code, stdout, stderr = runCLI(t, "graph", "--config", config, "-legend", "only", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `label = "Legend"`)
+}
+
+func TestRunLayout(t *testing.T) {
+	config := writeFile(t, "config.yaml", "layout:\n  rankdir: LR\n  concentrate: true\n")
+
+	code, stdout, stderr := runCLI(t, "graph", "--config", config, "-ranksep", "1.5", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `  graph [fontname = "arial" rankdir = "LR" ranksep = "1.5" concentrate = "true"];`)
+
+	// flags take precedence over the config, even when set to their zero value
+	code, stdout, stderr = runCLI(t, "graph", "--config", config, "-rankdir", "BT", "-concentrate=false", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `  graph [fontname = "arial" rankdir = "BT"];`)
+
+	code, _, stderr = runCLI(t, "graph", "--config", writeFile(t, "config.yaml", "layout:\n  splines: wavy\n"), "examples/simple.yaml")
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "invalid splines 'wavy'")
+}
+
+func TestRunAnnotations(t *testing.T) {
+	config := writeFile(t, "config.yaml", "annotations:\n  url: https://git.example/{{.File}}#L{{.Line}}\n")
+
+	code, stdout, stderr := runCLI(t, "graph", "--config", config, "-tooltips", "-url-target", "_blank", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `tooltip = "image: postgres:17\nports: 5432:5432\nmounts: my-volume:/var/lib/postgresql/data (rw)" URL = "https://git.example/examples/simple.yaml#L5" target = "_blank"];`)
+	assert.Contains(t, stdout, `tooltip="my-service → my-database: service_healthy"];`)
+
+	code, _, stderr = runCLI(t, "graph", "-url-template", "{{.Path}}", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "invalid url template")
+}
+
+func TestRunConfig(t *testing.T) {
+	config := writeFile(t, "config.yaml", "format: tree\nexclude: [my-volume]\n")
+
+	code, stdout, stderr := runCLI(t, "graph", "--config", config, "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "simple.yaml\n└── my-service\n    └── my-database (healthy)\n", stdout)
+
+	// flags take precedence over the config
+	code, stdout, _ = runCLI(t, "graph", "--config", config, "--format", "mermaid", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(stdout, "flowchart TB"))
+
+	code, _, stderr = runCLI(t, "graph", "--config", writeFile(t, "config.yaml", "fromat: tree\n"), "examples/simple.yaml")
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "Error ::")
+
+	// the invalid values of the config are parse errors, without the usage of the flags
+	for _, content := range []string{
+		"format: png\n",
+		"theme: not-a-theme\n",
+		"legend: sideways\n",
+		"categories:\n  my-database: not-a-category\n",
+		"exclude: ['[']\n",
+		"annotations:\n  url: '{{.Path}}'\n",
+	} {
+		code, _, stderr = runCLI(t, "graph", "--config", writeFile(t, "config.yaml", content), "examples/simple.yaml")
+		assert.Equal(t, exitParse, code, content)
+		assert.Contains(t, stderr, "Error :: invalid config", content)
+		assert.NotContains(t, stderr, "Usage", content)
+	}
+
+	// unlike the same values given as flags
+	code, _, stderr = runCLI(t, "graph", "--config", config, "-theme", "not-a-theme", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Usage")
+}
+
+func TestRunParseError(t *testing.T) {
+	code, _, stderr := runCLI(t, "validate", writeFile(t, "docker-compose.yaml", "services: ["))
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "Error :: could not parse")
+}
+
+func TestRunValidate(t *testing.T) {
+	code, stdout, _ := runCLI(t, "validate", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stdout)
+
+	invalid := writeFile(t, "docker-compose.yaml", `
+services:
+  a:
+    depends_on: [b, missing]
+  b:
+    depends_on: [a]
+`)
+
+	code, stdout, stderr := runCLI(t, "validate", invalid)
+	assert.Equal(t, exitFailure, code)
+	assert.Equal(t, ""+
+		"docker-compose.yaml: a: depends on undefined service 'missing'\n"+
+		"docker-compose.yaml: a: dependency cycle: a -> b -> a\n", stdout)
+	assert.Equal(t, "Error :: found 2 problem(s)\n", stderr)
+
+	code, _, _ = runCLI(t, "order", invalid)
+	assert.Equal(t, exitFailure, code)
+}
+
+func TestRunOrder(t *testing.T) {
+	code, stdout, _ := runCLI(t, "order", "examples/with-labels.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "1. my-database\n2. my-service\n3. my-service-init, my-service-requestor\n", stdout)
+}
+
+func TestRunDiff(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "diff", "examples/simple.yaml", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCLI(t, "diff", "examples/simple.yaml", "examples/with-labels.yaml")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stdout, "+ service my-service-init (script)\n")
+	assert.Empty(t, stderr)
+
+	// sets of inputs separated by '--'
+	code, stdout, _ = runCLI(t, "diff", "examples/simple.yaml", "--", "examples/simple.yaml", "examples/with-labels.yaml")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stdout, "+ volume my-volume\n")
+
+	code, _, stderr = runCLI(t, "diff", "examples/simple.yaml", "--")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected compose files on both sides of '--'")
+
+	// the graph formats render the combined graph, the summary goes to stderr
+	code, stdout, stderr = runCLI(t, "diff", "-format", "dot", "examples/simple.yaml", "examples/with-labels.yaml")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stdout, `fillcolor = "/bugn8/7"   color = "/bugn8/8"   fontcolor = "white"      label = "my-service-init"];`)
+	assert.Contains(t, stdout, `label = "added"];`)
+	assert.Contains(t, stderr, "+ service my-service-init (script)\n")
+}
+
+func TestRunStats(t *testing.T) {
+	code, stdout, _ := runCLI(t, "stats", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, ""+
+		"files:           1\n"+
+		"services:        2\n"+
+		"volumes:         1\n"+
+		"dependencies:    1\n"+
+		"mounts:          2\n"+
+		"stages:          2\n"+
+		"health gates:    1\n"+
+		"longest chain:   my-service -> my-database (depth 1)\n"+
+		"orphans:         none\n"+
+		"unused volumes:  none\n"+
+		"cycles:          none\n"+
+		"\n"+
+		"service      fan-in  fan-out\n"+
+		"my-database  1       0\n"+
+		"my-service   0       1\n", stdout)
+
+	input := writeFile(t, "compose.yaml", "services:\n  a:\n    depends_on: [b]\n  b:\n    depends_on: [a]\n  c: {}\n")
+
+	code, stdout, stderr := runCLI(t, "stats", "-format", "json", input)
+	require.Equal(t, exitOK, code, stderr)
+
+	var s jsonStats
+	require.NoError(t, json.Unmarshal([]byte(stdout), &s))
+	assert.Equal(t, [][]string{{"a", "b"}}, s.Cycles)
+	assert.Equal(t, []string{"c"}, s.Orphans)
+	assert.Empty(t, s.LongestChain)
+	assert.Equal(t, jsonServiceStats{Name: "a", Group: "compose.yaml", FanIn: 1, FanOut: 1}, s.PerService[0])
+}
+
+func TestRunHeatmap(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "graph", "-heatmap", "fan-in", "examples/simple.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `fillcolor = "/orrd9/9"`)
+	assert.Contains(t, stdout, `label = "fan-in: 1"`)
+
+	code, _, stderr = runCLI(t, "graph", "-heatmap", "fan-out-in", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown metric 'fan-out-in'")
+
+	code, _, stderr = runCLI(t, "graph", "-heatmap", "fan-in", "-format", "mermaid", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "only supported by the dot format")
+}
+
+func TestRunDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, contents := range map[string]string{
+		"services/api/docker-compose.yml":          "services:\n  api:\n    depends_on: [db]\n  db:\n    image: postgres\n",
+		"services/api/docker-compose.override.yml": "services:\n  api:\n    depends_on: [cache]\n  cache:\n    image: redis\n",
+		"services/web/compose.yaml":                "services:\n  web:\n    image: nginx\n",
+		"services/legacy/compose.yaml":             "services:\n  legacy:\n    image: nginx\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+
+	root := filepath.Join(dir, "services")
+
+	code, _, stderr := runCLI(t, "order", root)
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "no compose files found")
+
+	code, stdout, stderr := runCLI(t, "order", "--recursive", "--ignore", "legacy/", root)
+	assert.Equal(t, exitOK, code, stderr)
+
+	label := filepath.ToSlash(root)
+	assert.Equal(t, fmt.Sprintf("1. %[1]s/api:cache, %[1]s/api:db, %[1]s/web:web\n2. %[1]s/api:api\n", label), stdout)
+}
+
+func TestRunStdin(t *testing.T) {
+	config := `
+name: my-project
+services:
+  my-service:
+    depends_on:
+      my-database:
+        condition: service_healthy
+        required: true
+        restart: true
+  my-database:
+    image: postgres
+`
+
+	code, stdout, stderr := runCLIWithStdin(t, config, "graph", "-format", "tree", "-")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "my-project\n└── my-service\n    └── my-database (healthy, restart)\n", stdout)
+
+	// the graph command is implied
+	code, stdout, stderr = runCLIWithStdin(t, config, "--format", "tree", "--label", "resolved", "-")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "resolved\n└── my-service\n    └── my-database (healthy, restart)\n", stdout)
+
+	// stdin can be compared against a file
+	code, _, _ = runCLIWithStdin(t, config, "diff", "-", "examples/simple.yaml")
+	assert.Equal(t, exitFailure, code)
+
+	code, _, stderr = runCLIWithStdin(t, config, "diff", "-", "-")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "can only be read once")
+
+	code, _, stderr = runCLIWithStdin(t, "services: [", "validate", "-")
+	assert.Equal(t, exitParse, code)
+	assert.Contains(t, stderr, "could not parse 'stdin'")
+}
@@ -0,0 +1,462 @@
+// Package composegraph loads docker compose projects, builds the graph of their
+// services, volumes & dependencies and renders it in any of the supported formats.
+//
+// A project is a set of compose files; each file becomes a group of nodes in
+// the graph. Files can be loaded from disk, from any fs.FS (e.g. a git tree or
+// an archive) or from an io.Reader:
+//
+//	project, err := composegraph.Load("docker-compose.yaml", "docker-compose.override.yaml")
+//	if err != nil {
+//		return err
+//	}
+//
+//	g, err := project.Graph(composegraph.Options{Theme: "monochrome"})
+//	if err != nil {
+//		return err
+//	}
+//
+//	return composegraph.Render(os.Stdout, g, "svg", composegraph.RenderOptions{})
+package composegraph
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/averche/docker-compose-graph/internal/compose"
+	"github.com/averche/docker-compose-graph/internal/graph"
+)
+
+type (
+	// Graph is the model consumed by the renderers: node groups (one per
+	// compose file) connected by typed edges
+	Graph     = graph.Graph
+	NodeGroup = graph.NodeGroup
+	Node      = graph.Node
+	NodeID    = graph.NodeID
+	Edge      = graph.Edge
+	EdgeKind  = graph.EdgeKind
+	Category  = graph.Category
+	Theme     = graph.Theme
+
+	// Legend places the legend of the dot, svg, draw.io & html formats
+	Legend = graph.Legend
+
+	// Metric is a per-service metric the dot format can color the services by (see RenderOptions.Heatmap)
+	Metric = graph.Metric
+
+	// RenderOptions control the formats which support them (e.g. colors in the tree format)
+	RenderOptions = graph.RenderOptions
+
+	// DOTLayout holds the graphviz attributes of the dot format (e.g. the direction of the ranks)
+	DOTLayout = graph.DOTLayout
+
+	// Annotations attach tooltips & hyperlinks to the nodes & edges of the dot & svg formats;
+	// the hyperlinks are executed against URLData
+	Annotations = graph.Annotations
+	URLData     = graph.URLData
+
+	// DirOptions control the discovery of the compose files within a directory
+	DirOptions = compose.DiscoverOptions
+
+	Problem      = graph.Problem
+	Finding      = graph.Finding
+	LintRule     = graph.LintRule
+	Severity     = graph.Severity
+	VolumeAccess = graph.VolumeAccess
+	Impact       = graph.Impact
+	Coupling     = graph.Coupling
+	Change       = graph.Change
+	ChangeType   = graph.ChangeType
+	DiffStatus   = graph.DiffStatus
+	Stats        = graph.Stats
+	ServiceStats = graph.ServiceStats
+)
+
+const (
+	EdgeKindDependency  = graph.EdgeKindDependency
+	EdgeKindVolumeMount = graph.EdgeKindVolumeMount
+
+	ChangeAdded    = graph.ChangeAdded
+	ChangeRemoved  = graph.ChangeRemoved
+	ChangeModified = graph.ChangeModified
+
+	DiffNone      = graph.DiffNone
+	DiffUnchanged = graph.DiffUnchanged
+	DiffAdded     = graph.DiffAdded
+	DiffRemoved   = graph.DiffRemoved
+	DiffChanged   = graph.DiffChanged
+
+	SeverityOff     = graph.SeverityOff
+	SeverityNote    = graph.SeverityNote
+	SeverityWarning = graph.SeverityWarning
+	SeverityError   = graph.SeverityError
+
+	LegendInline = graph.LegendInline
+	LegendHidden = graph.LegendHidden
+	LegendOnly   = graph.LegendOnly
+
+	MetricNone   = graph.MetricNone
+	MetricFanIn  = graph.MetricFanIn
+	MetricFanOut = graph.MetricFanOut
+
+	// CategoryVolume is the category of the volumes, the other nodes being services
+	CategoryVolume = graph.CategoryVolume
+
+	CouplingNone            = graph.CouplingNone
+	CouplingOptional        = graph.CouplingOptional
+	CouplingSharedVolume    = graph.CouplingSharedVolume
+	CouplingStartOnly       = graph.CouplingStartOnly
+	CouplingCompletionGated = graph.CouplingCompletionGated
+	CouplingHealthGated     = graph.CouplingHealthGated
+	CouplingRestart         = graph.CouplingRestart
+)
+
+// Project is a set of compose files; the zero value is an empty project ready to be added to
+type Project struct {
+	sources []graph.Source
+}
+
+// Load parses the compose files at the given paths into a project; directories are searched
+// for the standard compose file names (see AddDir)
+func Load(paths ...string) (*Project, error) {
+	var p Project
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s': %w", path, err)
+		}
+
+		if info.IsDir() {
+			err = p.AddDir(path, DirOptions{})
+		} else {
+			err = p.AddFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &p, nil
+}
+
+// LoadFS parses the compose files at the given paths within the file system into a project
+func LoadFS(fsys fs.FS, paths ...string) (*Project, error) {
+	var p Project
+
+	for _, path := range paths {
+		if err := p.AddFS(fsys, path); err != nil {
+			return nil, err
+		}
+	}
+
+	return &p, nil
+}
+
+// AddFile parses the compose file at the given path & adds it to the project, labelled with its base name
+func (p *Project) AddFile(path string) error {
+	f, err := compose.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("could not parse '%s': %w", path, err)
+	}
+
+	p.add(filepath.Base(path), f)
+
+	return nil
+}
+
+// AddFS parses the compose file at the given path within the file system & adds it to the project,
+// labelled with its base name
+func (p *Project) AddFS(fsys fs.FS, name string) error {
+	f, err := compose.ParseFS(fsys, name)
+	if err != nil {
+		return fmt.Errorf("could not parse '%s': %w", name, err)
+	}
+
+	p.add(path.Base(name), f)
+
+	return nil
+}
+
+// AddDir discovers the compose projects in the directory (& its subdirectories if recursive), adding
+// each of them labelled with its directory; a compose file is merged with its override file if any
+func (p *Project) AddDir(dir string, options DirOptions) error {
+	name := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+
+	return p.addDir(os.DirFS(dir), ".", filepath.ToSlash(filepath.Clean(dir)), name, options)
+}
+
+// WatchedFiles returns the files within the directory whose changes may change what AddDir
+// discovers (the compose files & the .gitignore files honored), without walking the ignored
+// subdirectories
+func WatchedFiles(dir string, options DirOptions) ([]string, error) {
+	files, err := compose.Watched(os.DirFS(dir), ".", options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range files {
+		files[i] = filepath.Join(dir, filepath.FromSlash(file))
+	}
+
+	return files, nil
+}
+
+// AddDirFS discovers the compose projects in the directory within the file system (see AddDir)
+func (p *Project) AddDirFS(fsys fs.FS, dir string, options DirOptions) error {
+	return p.addDir(fsys, dir, dir, path.Base(dir), options)
+}
+
+func (p *Project) addDir(fsys fs.FS, root, prefix, name string, options DirOptions) error {
+	projects, err := compose.Discover(fsys, root, options)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		files := make([]compose.File, 0, len(project.Files))
+
+		for _, file := range project.Files {
+			location := path.Join(prefix, strings.TrimPrefix(file, root+"/"))
+
+			f, err := compose.ParseFS(fsys, file)
+			if err != nil {
+				return fmt.Errorf("could not parse '%s': %w", location, err)
+			}
+			files = append(files, f.WithPath(location))
+		}
+
+		label := path.Join(prefix, project.Dir)
+		if label == "." {
+			label = name
+		}
+
+		p.add(label, compose.Merge(files...))
+	}
+
+	return nil
+}
+
+// AddReader parses the compose file contents from the reader (e.g. the output of 'docker compose config')
+// & adds it to the project with the given label; if empty, the label defaults to the project name
+// declared in the file or "stdin"
+func (p *Project) AddReader(label string, r io.Reader) error {
+	f, err := compose.Parse(r)
+	if err != nil {
+		return fmt.Errorf("could not parse '%s': %w", cmp.Or(label, "stdin"), err)
+	}
+
+	p.add(cmp.Or(label, f.Name, "stdin"), f)
+
+	return nil
+}
+
+func (p *Project) add(label string, f compose.File) {
+	p.sources = append(p.sources, graph.Source{Label: label, File: f})
+}
+
+// Options control how the graph is built from the project
+type Options struct {
+	// Include keeps only the services & volumes whose names match one of the
+	// glob patterns (see path.Match); all the nodes are kept if empty
+	Include []string
+
+	// Exclude removes the services & volumes whose names match one of the glob patterns
+	Exclude []string
+
+	// Focus keeps only the services & volumes whose names match one of the glob patterns along with
+	// their dependencies, mounted volumes & dependents (transitively); applied after the filters above
+	Focus []string
+
+	// Categories overrides the category of the services & volumes by name (e.g. "db" => "database"),
+	// taking precedence over the 'graph.node.category' label
+	Categories map[string]string
+
+	// Theme is the name of a built-in theme (see Themes); the default theme is used if empty
+	Theme string
+
+	// Legend is the placement of the legend: "inline" (the default if empty), "none" or "only"
+	Legend string
+}
+
+// Graph builds the graph of the project; edges to filtered out nodes are removed along with them
+func (p *Project) Graph(options Options) (Graph, error) {
+	g := graph.New(p.sources...)
+
+	if options.Theme != "" {
+		theme, err := graph.LookupTheme(options.Theme)
+		if err != nil {
+			return Graph{}, err
+		}
+		g.Theme = theme
+	}
+
+	if options.Legend != "" {
+		legend, err := graph.ParseLegend(options.Legend)
+		if err != nil {
+			return Graph{}, err
+		}
+		g.Legend = legend
+	}
+
+	// sorted to report the same error first
+	for _, name := range slices.Sorted(maps.Keys(options.Categories)) {
+		c := options.Categories[name]
+
+		category, ok := graph.ParseCategory(c)
+		if !ok {
+			return Graph{}, fmt.Errorf("unknown category '%s' for '%s'", c, name)
+		}
+
+		for gi := range g.Groups {
+			for ni := range g.Groups[gi].Nodes {
+				if g.Groups[gi].Nodes[ni].Name == name {
+					g.Groups[gi].Nodes[ni].Category = category
+					g.Groups[gi].Nodes[ni].CategoryConfigured = true
+				}
+			}
+		}
+	}
+
+	// validate the patterns up front since path.Match only reports malformed patterns on a mismatch
+	for _, pattern := range slices.Concat(options.Include, options.Exclude, options.Focus) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Graph{}, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+
+	if len(options.Include) != 0 || len(options.Exclude) != 0 {
+		g = g.Filter(func(_ NodeID, node Node) bool {
+			if len(options.Include) != 0 && !matchAny(options.Include, node.Name) {
+				return false
+			}
+			return !matchAny(options.Exclude, node.Name)
+		})
+	}
+
+	if len(options.Focus) != 0 {
+		var focused []NodeID
+
+		for gi, group := range g.Groups {
+			for _, node := range group.Nodes {
+				if matchAny(options.Focus, node.Name) {
+					focused = append(focused, NodeID{Group: gi, Name: node.Name})
+				}
+			}
+		}
+
+		related := g.Related(focused...)
+
+		g = g.Filter(func(id NodeID, _ Node) bool {
+			return related[id]
+		})
+	}
+
+	return g, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Render writes the graph to w in the given format (see Formats)
+func Render(w io.Writer, g Graph, format string, options RenderOptions) error {
+	renderer, err := graph.NewRenderer(format, options)
+	if err != nil {
+		return err
+	}
+
+	return renderer.Render(w, g)
+}
+
+// Formats returns the sorted list of the supported output formats
+func Formats() []string {
+	return graph.Formats()
+}
+
+// FormatFromPath infers the output format from the extension of the given file path
+func FormatFromPath(path string) (string, bool) {
+	return graph.FormatFromPath(path)
+}
+
+// Themes returns the sorted list of the built-in themes
+func Themes() []string {
+	return graph.Themes()
+}
+
+// Validate checks the graph for dependencies on undefined services, mounts of undeclared volumes & dependency cycles
+func Validate(g Graph) []Problem {
+	return graph.Validate(g)
+}
+
+// Lint checks the graph against the opinionated lint rules (see LintRules), with the given severities
+// (by rule id) overriding the default ones; a service can suppress rules with its 'graph.lint.ignore'
+// label (comma-separated rule ids)
+func Lint(g Graph, severities map[string]Severity) []Finding {
+	return graph.Lint(g, severities)
+}
+
+// VolumeAccesses returns the services writing & reading each volume of the graph; a volume written
+// by more than one service is contended (see VolumeAccess.Contended)
+func VolumeAccesses(g Graph) []VolumeAccess {
+	return graph.VolumeAccesses(g)
+}
+
+// ImpactOf returns the services affected by the failure of the given ones through their dependencies
+// & the volumes mounted read-write, ranked by how strongly they are coupled to the failed services
+func ImpactOf(g Graph, failed ...NodeID) []Impact {
+	return graph.ImpactOf(g, failed...)
+}
+
+// LintRules returns the lint rules along with their default severities
+func LintRules() []LintRule {
+	return graph.LintRules()
+}
+
+// ParseSeverity returns the severity with the given name ("off", "note", "warning" or "error")
+func ParseSeverity(s string) (Severity, bool) {
+	return graph.ParseSeverity(s)
+}
+
+// StartupOrder returns the services grouped into the stages they would be started in
+func StartupOrder(g Graph) ([][]NodeID, error) {
+	return graph.StartupOrder(g)
+}
+
+// Diff returns the services, volumes & edges added, removed or modified between the two graphs
+func Diff(before, after Graph) []Change {
+	return graph.Diff(before, after)
+}
+
+// DiffGraph combines the two graphs into one whose nodes & edges are marked as unchanged, added,
+// removed or changed; it can be rendered in every format, the changes being highlighted
+func DiffGraph(before, after Graph) Graph {
+	return graph.DiffGraph(before, after)
+}
+
+// ComputeStats returns the aggregate metrics of the graph along with the fan-in & fan-out of each
+// service
+func ComputeStats(g Graph) Stats {
+	return graph.ComputeStats(g)
+}
+
+// ParseMetric returns the metric with the given name ("none", "fan-in" or "fan-out")
+func ParseMetric(name string) (Metric, error) {
+	return graph.ParseMetric(name)
+}
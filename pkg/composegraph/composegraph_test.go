@@ -0,0 +1,255 @@
+package composegraph
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/averche/docker-compose-graph/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCompose = `
+services:
+  my-service:
+    depends_on:
+      - my-database
+      - my-tool
+    volumes:
+      - my-volume:/data
+  my-database:
+    image: postgres:16
+  my-tool:
+    image: busybox
+volumes:
+  my-volume:
+`
+
+func names(g Graph) []string {
+	var names []string
+	for _, group := range g.Groups {
+		for _, node := range group.Nodes {
+			names = append(names, group.Label+"/"+node.Name)
+		}
+	}
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	project, err := Load("../../examples/simple.yaml")
+	require.NoError(t, err)
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+	require.Len(t, g.Groups, 1)
+	assert.Equal(t, "simple.yaml", g.Groups[0].Label)
+	assert.NotEmpty(t, g.Groups[0].Nodes)
+
+	_, err = Load("../../examples/missing.yaml")
+	require.ErrorContains(t, err, "missing.yaml")
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/docker-compose.yaml": {Data: []byte(testCompose)},
+		"b/docker-compose.yaml": {Data: []byte("services:\n  other:\n    image: busybox\n")},
+	}
+
+	project, err := LoadFS(fsys, "a/docker-compose.yaml", "b/docker-compose.yaml")
+	require.NoError(t, err)
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker-compose.yaml/my-database",
+		"docker-compose.yaml/my-service",
+		"docker-compose.yaml/my-tool",
+		"docker-compose.yaml/my-volume",
+		"docker-compose.yaml/other",
+	}, names(g))
+
+	_, err = LoadFS(fsys, "c/docker-compose.yaml")
+	require.Error(t, err)
+}
+
+func TestAddReader(t *testing.T) {
+	var project Project
+
+	require.NoError(t, project.AddReader("stdin", strings.NewReader(testCompose)))
+	require.Error(t, project.AddReader("broken", strings.NewReader("services: [")))
+
+	// the label defaults to the project name, if any
+	require.NoError(t, project.AddReader("", strings.NewReader("name: my-project\n"+testCompose)))
+	require.NoError(t, project.AddReader("", strings.NewReader(testCompose)))
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+	require.Len(t, g.Groups, 3)
+	assert.Equal(t, "stdin", g.Groups[0].Label)
+	assert.Equal(t, "my-project", g.Groups[1].Label)
+	assert.Equal(t, "stdin", g.Groups[2].Label)
+	assert.Len(t, g.Edges, 9)
+}
+
+func TestGraphOptions(t *testing.T) {
+	var project Project
+	require.NoError(t, project.AddReader("docker-compose.yaml", strings.NewReader(testCompose)))
+
+	g, err := project.Graph(Options{Exclude: []string{"*-tool"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker-compose.yaml/my-database",
+		"docker-compose.yaml/my-service",
+		"docker-compose.yaml/my-volume",
+	}, names(g))
+	assert.Len(t, g.Edges, 2)
+
+	g, err = project.Graph(Options{Include: []string{"my-s*", "my-d*"}, Exclude: []string{"my-database"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker-compose.yaml/my-service"}, names(g))
+	assert.Empty(t, g.Edges)
+
+	g, err = project.Graph(Options{Categories: map[string]string{"my-tool": "script"}, Theme: "monochrome"})
+	require.NoError(t, err)
+	node, ok := g.Node(NodeID{Group: 0, Name: "my-tool"})
+	require.True(t, ok)
+	assert.Equal(t, graph.CategoryScript, node.Category)
+	assert.Equal(t, graph.MonochromeTheme, g.Theme)
+
+	_, err = project.Graph(Options{Categories: map[string]string{"my-tool": "not-a-category"}})
+	require.ErrorContains(t, err, "not-a-category")
+
+	_, err = project.Graph(Options{Theme: "not-a-theme"})
+	require.ErrorContains(t, err, "not-a-theme")
+
+	// the legend placement survives the filtering
+	g, err = project.Graph(Options{Exclude: []string{"my-tool"}, Legend: "none"})
+	require.NoError(t, err)
+	assert.Equal(t, LegendHidden, g.Legend)
+
+	_, err = project.Graph(Options{Legend: "not-a-legend"})
+	require.ErrorContains(t, err, "not-a-legend")
+
+	_, err = project.Graph(Options{Include: []string{"["}})
+	require.ErrorContains(t, err, "invalid pattern")
+
+	// the focus keeps the related nodes only, after the filters
+	g, err = project.Graph(Options{Focus: []string{"my-tool"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker-compose.yaml/my-service", "docker-compose.yaml/my-tool"}, names(g))
+
+	g, err = project.Graph(Options{Exclude: []string{"my-service"}, Focus: []string{"my-tool"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker-compose.yaml/my-tool"}, names(g))
+
+	_, err = project.Graph(Options{Focus: []string{"["}})
+	require.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestRender(t *testing.T) {
+	var project Project
+	require.NoError(t, project.AddReader("docker-compose.yaml", strings.NewReader(testCompose)))
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+
+	for _, format := range Formats() {
+		var b strings.Builder
+		require.NoError(t, Render(&b, g, format, RenderOptions{}), format)
+		assert.Contains(t, b.String(), "my-database", format)
+	}
+
+	require.Error(t, Render(&strings.Builder{}, g, "not-a-format", RenderOptions{}))
+}
+
+func TestAddDirFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"repo/services/api/docker-compose.yml": {Data: []byte(testCompose)},
+		"repo/services/api/docker-compose.override.yml": {Data: []byte(`
+services:
+  my-cache:
+    image: redis
+  my-service:
+    depends_on:
+      - my-cache
+`)},
+		"repo/services/web/docker-compose.yml": {Data: []byte("services:\n  web:\n    image: nginx\n")},
+		"repo/services/old/docker-compose.yml": {Data: []byte("services:\n  old:\n    image: nginx\n")},
+	}
+
+	var project Project
+	require.NoError(t, project.AddDirFS(fsys, "repo/services", DirOptions{Recursive: true, Ignore: []string{"old"}}))
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+	require.Len(t, g.Groups, 2)
+	assert.Equal(t, "repo/services/api", g.Groups[0].Label)
+	assert.Equal(t, "repo/services/web", g.Groups[1].Label)
+
+	// the override file is merged into the project
+	assert.Len(t, g.Outgoing(NodeID{Group: 0, Name: "my-service"}), 4)
+
+	// without recursion, the directory itself must contain a compose file
+	require.ErrorContains(t, project.AddDirFS(fsys, "repo/services", DirOptions{}), "no compose files found")
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-project")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(testCompose), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "compose.yaml"), []byte(testCompose), 0o644))
+
+	// directories are not searched recursively by default
+	project, err := Load(dir)
+	require.NoError(t, err)
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+	require.Len(t, g.Groups, 1)
+	assert.Equal(t, filepath.ToSlash(dir), g.Groups[0].Label)
+
+	require.NoError(t, project.AddDir(dir, DirOptions{Recursive: true}))
+
+	g, err = project.Graph(Options{})
+	require.NoError(t, err)
+	require.Len(t, g.Groups, 3)
+	assert.Equal(t, filepath.ToSlash(dir)+"/nested", g.Groups[2].Label)
+}
+
+func TestLint(t *testing.T) {
+	var project Project
+	require.NoError(t, project.AddReader("compose.yaml", strings.NewReader(testCompose)))
+
+	g, err := project.Graph(Options{})
+	require.NoError(t, err)
+
+	severity, ok := ParseSeverity("off")
+	require.True(t, ok)
+
+	var rules []string
+	for _, f := range Lint(g, map[string]Severity{"missing-category-label": severity}) {
+		rules = append(rules, f.Node.Name+": "+f.Rule)
+	}
+
+	assert.Equal(t, []string{
+		"my-service: database-dependency-not-healthy",
+		"my-tool: latest-image-tag",
+	}, rules)
+	assert.Len(t, LintRules(), 6)
+
+	// the services categorized by the config do not need a label
+	g, err = project.Graph(Options{Categories: map[string]string{"my-service": "service2", "my-tool": "tool"}})
+	require.NoError(t, err)
+
+	rules = nil
+	for _, f := range Lint(g, nil) {
+		if f.Rule == "missing-category-label" {
+			rules = append(rules, f.Node.Name+": "+f.Rule)
+		}
+	}
+
+	assert.Equal(t, []string{"my-database: missing-category-label"}, rules)
+}
@@ -0,0 +1,86 @@
+package composegraph_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing/fstest"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+func Example() {
+	var project composegraph.Project
+
+	err := project.AddReader("docker-compose.yaml", strings.NewReader(`
+services:
+  app:
+    depends_on:
+      db:
+        condition: service_healthy
+    volumes:
+      - cache:/var/cache
+  db:
+    image: postgres:16
+volumes:
+  cache:
+`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	g, err := project.Graph(composegraph.Options{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := composegraph.Render(os.Stdout, g, "tree", composegraph.RenderOptions{}); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// docker-compose.yaml
+	// └── app
+	//     ├── db (healthy)
+	//     └── cache [rw]
+}
+
+func ExampleLoadFS() {
+	fsys := fstest.MapFS{
+		"deploy/compose.yaml": {Data: []byte(`
+services:
+  api:
+    depends_on:
+      - db
+  db:
+    image: postgres:16
+  debug-tool:
+    image: busybox
+`)},
+	}
+
+	project, err := composegraph.LoadFS(fsys, "deploy/compose.yaml")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	g, err := project.Graph(composegraph.Options{
+		Exclude:    []string{"*-tool"},
+		Categories: map[string]string{"db": "database"},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, group := range g.Groups {
+		for _, node := range group.Nodes {
+			fmt.Printf("%s/%s: %s\n", group.Label, node.Name, node.Category)
+		}
+	}
+	// Output:
+	// compose.yaml/api: service1
+	// compose.yaml/db: database
+}
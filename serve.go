@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+//go:embed serve.html
+var servePage []byte
+
+var serveCommand = command{
+	name:    "serve",
+	args:    "<compose-file|dir>...",
+	summary: "serve the graph in every format over http, pushing updates to the browser whenever the inputs change",
+	formats: textFormats,
+	setup: func(fs *flag.FlagSet) func(c *cli, args []string) error {
+		addr := fs.String("addr", "localhost:8080", "address to listen on")
+
+		return func(c *cli, args []string) error {
+			switch {
+			case len(args) == 0:
+				return usageError("no compose files given")
+			case slices.Contains(args, "-"):
+				return usageError("stdin ('-') cannot be served")
+			case c.output != "":
+				return usageError("the serve command has no output file (-o)")
+			}
+
+			listener, err := net.Listen("tcp", *addr)
+			if err != nil {
+				return fmt.Errorf("could not listen on '%s': %w", *addr, err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			s := newServer(c, args)
+
+			server := &http.Server{
+				Handler:           s.handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+				// the event streams end along with the server
+				BaseContext: func(net.Listener) context.Context { return ctx },
+			}
+
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+
+			go func() {
+				_ = c.watch(ctx, args, watchInterval, func() error {
+					if err := s.reload(); err != nil {
+						return err
+					}
+					fmt.Fprintf(c.stderr, "%s reloaded %s\n", time.Now().Format(time.TimeOnly), strings.Join(args, " "))
+					return nil
+				})
+			}()
+
+			fmt.Fprintf(c.stderr, "serving on http://%s\n", listener.Addr())
+
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		}
+	},
+}
+
+// contentTypes are the media types of the formats served as other than plain text
+var contentTypes = map[string]string{
+	"drawio": "application/xml",
+	"html":   "text/html; charset=utf-8",
+	"json":   "application/json",
+	"svg":    "image/svg+xml",
+}
+
+// server holds the last project successfully loaded from the inputs & notifies the event streams
+// of each reload
+type server struct {
+	c     *cli
+	paths []string
+
+	mu      sync.Mutex
+	project *composegraph.Project
+	err     error // of the last reload
+	version int
+	streams map[chan struct{}]bool
+}
+
+func newServer(c *cli, paths []string) *server {
+	return &server{
+		c:       c,
+		paths:   paths,
+		streams: make(map[chan struct{}]bool),
+	}
+}
+
+// reload parses the inputs again; on errors, the previous project is kept
+func (s *server) reload() error {
+	project, err := s.c.loadProject(s.paths)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.project = &project
+	}
+
+	s.err = err
+	s.version++
+
+	for stream := range s.streams {
+		select {
+		case stream <- struct{}{}:
+		default: // the stream has yet to send the previous update
+		}
+	}
+
+	return err
+}
+
+func (s *server) state() (*composegraph.Project, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.project, s.version, s.err
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(servePage)
+	})
+	mux.HandleFunc("GET /events", s.events)
+	mux.HandleFunc("GET /{file}", s.render)
+
+	return mux
+}
+
+// render serves the graph in the format named by the extension of the requested file (e.g.
+// /graph.svg or /graph.mmd) or by its full name (e.g. /graph.tree)
+func (s *server) render(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.PathValue("file"), "graph.")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	format, ok := composegraph.FormatFromPath(r.PathValue("file"))
+	if !ok && slices.Contains(composegraph.Formats(), name) {
+		format, ok = name, true
+	}
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported format '%s' (available: %s)", name, strings.Join(composegraph.Formats(), ", ")), http.StatusNotFound)
+		return
+	}
+
+	project, _, err := s.state()
+	if project == nil {
+		http.Error(w, fmt.Sprintf("the inputs could not be loaded: %v", cmp.Or(err, errors.New("loading"))), http.StatusServiceUnavailable)
+		return
+	}
+
+	options, renderOptions, err := s.options(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the legend of the config only applies to the formats drawing one
+	if options.Legend == "" && slices.Contains(legendFormats, format) {
+		options.Legend = s.c.config.Legend
+	}
+
+	g, err := project.Graph(options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var b bytes.Buffer
+
+	if err := composegraph.Render(&b, g, format, renderOptions); err != nil {
+		http.Error(w, fmt.Sprintf("could not render: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", cmp.Or(contentTypes[format], "text/plain; charset=utf-8"))
+	_, _ = w.Write(b.Bytes())
+}
+
+// options returns the options of the flags & the config, overridden by the query parameters
+func (s *server) options(query url.Values) (composegraph.Options, composegraph.RenderOptions, error) {
+	options := s.c.options()
+	renderOptions := composegraph.RenderOptions{
+		Layout:      composegraph.DOTLayout(s.c.config.Layout),
+		Annotations: composegraph.Annotations(s.c.config.Annotations),
+	}
+
+	for name, list := range map[string]*[]string{
+		"include": &options.Include,
+		"exclude": &options.Exclude,
+		"focus":   &options.Focus,
+	} {
+		if values, ok := query[name]; ok {
+			*list = nil
+			for _, value := range values {
+				*list = append(*list, strings.Split(value, ",")...)
+			}
+		}
+	}
+
+	options.Theme = cmp.Or(query.Get("theme"), options.Theme)
+	options.Legend = cmp.Or(query.Get("legend"), options.Legend)
+	renderOptions.Layout.RankDir = cmp.Or(query.Get("rankdir"), renderOptions.Layout.RankDir)
+
+	for name, value := range map[string]*bool{
+		"reverse":     &renderOptions.Reverse,
+		"edge-labels": &renderOptions.EdgeLabels,
+		"tooltips":    &renderOptions.Annotations.Tooltips,
+	} {
+		if query.Has(name) {
+			b, err := strconv.ParseBool(cmp.Or(query.Get(name), "true"))
+			if err != nil {
+				return composegraph.Options{}, composegraph.RenderOptions{}, fmt.Errorf("invalid %s '%s'", name, query.Get(name))
+			}
+			*value = b
+		}
+	}
+
+	if query.Has("heatmap") {
+		metric, err := composegraph.ParseMetric(query.Get("heatmap"))
+		if err != nil {
+			return composegraph.Options{}, composegraph.RenderOptions{}, err
+		}
+		renderOptions.Heatmap = metric
+	}
+
+	if err := renderOptions.Layout.Validate(); err != nil {
+		return composegraph.Options{}, composegraph.RenderOptions{}, err
+	}
+
+	return options, renderOptions, nil
+}
+
+// events streams the reloads as server-sent events: 'update' with the version of the inputs once
+// they are loaded, 'failure' with the error otherwise; the current state is sent first
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	stream := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	s.streams[stream] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, stream)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for {
+		project, version, err := s.state()
+
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "event: failure\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", "\ndata: "))
+		case project != nil:
+			fmt.Fprintf(w, "event: update\ndata: %d\n\n", version)
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stream:
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/averche/docker-compose-graph/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "compose.yaml")
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  web:\n    depends_on: [api]\n  api:\n    depends_on: [db]\n  db: {}\n  tool: {}\n"), 0o644))
+
+	s := newServer(&cli{}, []string{input})
+
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	get := func(path string) (int, string, string) {
+		t.Helper()
+
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(b)
+	}
+
+	// nothing is served until the inputs are loaded
+	code, _, _ := get("/graph.dot")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	require.NoError(t, s.reload())
+
+	code, contentType, body := get("/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+	assert.Contains(t, body, `new EventSource("events")`)
+
+	// the formats are named by their extensions or their names
+	code, _, body = get("/graph.dot")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.HasPrefix(body, "digraph compose {"))
+
+	code, _, body = get("/graph.mmd")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.HasPrefix(body, "flowchart TB"))
+
+	code, contentType, body = get("/graph.svg")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "image/svg+xml", contentType)
+	assert.Contains(t, body, "<svg")
+
+	code, _, body = get("/graph.tree?reverse")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "│   └── api (started)\n│       └── web (started)\n")
+
+	// the query parameters filter the graph
+	code, contentType, body = get("/graph.json?focus=api&exclude=web")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "application/json", contentType)
+	assert.Contains(t, body, `"name": "api"`)
+	assert.Contains(t, body, `"name": "db"`)
+	assert.NotContains(t, body, `"name": "web"`)
+	assert.NotContains(t, body, `"name": "tool"`)
+
+	code, _, body = get("/graph.dot?heatmap=fan-in")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `label = "fan-in: 1"`)
+
+	code, _, body = get("/graph.dot?heatmap=size")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "unknown metric 'size'")
+
+	code, _, body = get("/graph.dot?rankdir=XY")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "rankdir")
+
+	code, _, _ = get("/graph.png")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _, _ = get("/other.dot")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// the reloads are streamed, the last good project being served on errors
+	resp, err := http.Get(ts.URL + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(resp.Body)
+	event := func() string {
+		t.Helper()
+
+		var lines []string
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, "event: update\ndata: 1\n", event())
+
+	require.NoError(t, os.WriteFile(input, []byte("services: [\n"), 0o644))
+	require.Error(t, s.reload())
+
+	assert.Contains(t, event(), "event: failure\ndata: could not parse")
+
+	code, _, body = get("/graph.dot")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "tool")
+
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  other: {}\n"), 0o644))
+	require.NoError(t, s.reload())
+
+	assert.Equal(t, "event: update\ndata: 3\n", event())
+}
+
+func TestServerConfigLegend(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "compose.yaml")
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  web: {}\n"), 0o644))
+
+	s := newServer(&cli{config: config.Config{Legend: "none"}}, []string{input})
+	require.NoError(t, s.reload())
+
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(b)
+	}
+
+	// the legend of the config applies to the formats drawing one, and is ignored by the others
+	code, body := get("/graph.dot")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, body, `label = "Legend"`)
+
+	code, body = get("/graph.dot?legend=only")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `label = "Legend"`)
+
+	code, body = get("/graph.tree")
+	assert.Equal(t, http.StatusOK, code, body)
+	assert.Contains(t, body, "web")
+}
+
+func TestRunServeUsage(t *testing.T) {
+	code, _, stderr := runCLI(t, "serve", "-")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "stdin ('-') cannot be served")
+
+	code, _, stderr = runCLI(t, "serve", "-addr", "256.0.0.1:0", "examples/simple.yaml")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "could not listen on '256.0.0.1:0'")
+}
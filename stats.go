@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+// printStats prints the metrics of the graph followed by the table of the fan-in & fan-out of
+// each service
+func printStats(w io.Writer, g composegraph.Graph, s composegraph.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "files:\t%d\n", s.Groups)
+	fmt.Fprintf(tw, "services:\t%d\n", s.Services)
+	fmt.Fprintf(tw, "volumes:\t%d\n", s.Volumes)
+	fmt.Fprintf(tw, "dependencies:\t%d\n", s.Dependencies)
+	fmt.Fprintf(tw, "mounts:\t%d\n", s.Mounts)
+	fmt.Fprintf(tw, "stages:\t%d\n", s.Stages)
+	fmt.Fprintf(tw, "health gates:\t%d\n", s.HealthGates)
+
+	switch {
+	case len(s.Components) != 0:
+		fmt.Fprintf(tw, "longest chain:\tunbounded (dependency cycles)\n")
+	case len(s.LongestChain) != 0:
+		fmt.Fprintf(tw, "longest chain:\t%s (depth %d)\n", strings.Join(displayNames(g, s.LongestChain), " -> "), s.Depth)
+	default:
+		fmt.Fprintf(tw, "longest chain:\tnone\n")
+	}
+
+	fmt.Fprintf(tw, "orphans:\t%s\n", listOrNone(displayNames(g, s.Orphans)))
+	fmt.Fprintf(tw, "unused volumes:\t%s\n", listOrNone(displayNames(g, s.UnusedVolumes)))
+
+	var cycles []string
+	for _, component := range s.Components {
+		cycles = append(cycles, "{"+strings.Join(displayNames(g, component), ", ")+"}")
+	}
+	fmt.Fprintf(tw, "cycles:\t%s\n", listOrNone(cycles))
+
+	if len(s.PerService) != 0 {
+		fmt.Fprintf(tw, "\nservice\tfan-in\tfan-out\n")
+
+		for _, service := range s.PerService {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", displayName(g, service.Node), service.FanIn, service.FanOut)
+		}
+	}
+
+	return tw.Flush()
+}
+
+type jsonStats struct {
+	Files         int                `json:"files"`
+	Services      int                `json:"services"`
+	Volumes       int                `json:"volumes"`
+	Dependencies  int                `json:"dependencies"`
+	Mounts        int                `json:"mounts"`
+	Stages        int                `json:"stages"`
+	HealthGates   int                `json:"healthGates"`
+	Depth         int                `json:"depth"`
+	LongestChain  []string           `json:"longestChain"`
+	Orphans       []string           `json:"orphans"`
+	UnusedVolumes []string           `json:"unusedVolumes"`
+	Cycles        [][]string         `json:"cycles"`
+	PerService    []jsonServiceStats `json:"perService"`
+}
+
+type jsonServiceStats struct {
+	Name   string `json:"name"`
+	Group  string `json:"group"`
+	FanIn  int    `json:"fanIn"`
+	FanOut int    `json:"fanOut"`
+}
+
+func printStatsJSON(w io.Writer, g composegraph.Graph, s composegraph.Stats) error {
+	out := jsonStats{
+		Files:         s.Groups,
+		Services:      s.Services,
+		Volumes:       s.Volumes,
+		Dependencies:  s.Dependencies,
+		Mounts:        s.Mounts,
+		Stages:        s.Stages,
+		HealthGates:   s.HealthGates,
+		Depth:         s.Depth,
+		LongestChain:  displayNames(g, s.LongestChain),
+		Orphans:       displayNames(g, s.Orphans),
+		UnusedVolumes: displayNames(g, s.UnusedVolumes),
+		Cycles:        [][]string{},
+		PerService:    make([]jsonServiceStats, 0, len(s.PerService)),
+	}
+
+	for _, component := range s.Components {
+		out.Cycles = append(out.Cycles, displayNames(g, component))
+	}
+
+	for _, service := range s.PerService {
+		out.PerService = append(out.PerService, jsonServiceStats{
+			Name:   service.Node.Name,
+			Group:  g.Groups[service.Node.Group].Label,
+			FanIn:  service.FanIn,
+			FanOut: service.FanOut,
+		})
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(out)
+}
+
+// displayNames returns the display names of the nodes (an empty list rather than nil)
+func displayNames(g composegraph.Graph, ids []composegraph.NodeID) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, displayName(g, id))
+	}
+	return names
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
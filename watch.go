@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+)
+
+// watchInterval is the delay between two polls of the inputs; a change is only rendered once a
+// poll finds no further change, which debounces the bursts of writes of editors & git checkouts
+const watchInterval = 500 * time.Millisecond
+
+// fileState identifies a version of a watched file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watch renders, then renders again whenever the given inputs change until the context is done; the
+// errors are printed rather than returned so that the last good output is kept until the inputs are
+// fixed (the config is only loaded once)
+func (c *cli) watch(ctx context.Context, paths []string, interval time.Duration, render func() error) error {
+	rendered := func() {
+		if err := render(); err != nil {
+			var e *cliError
+			if errors.As(err, &e) {
+				err = e.err
+			}
+			fmt.Fprintf(c.stderr, "Error :: %v\n", err)
+		}
+	}
+
+	previous := c.snapshot(paths)
+	rendered()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current := c.snapshot(paths)
+
+		switch {
+		case !maps.Equal(current, previous):
+			previous, pending = current, true
+		case pending:
+			pending = false
+			rendered()
+		}
+	}
+}
+
+// snapshot returns the state of the watched files: the given compose files & the files discovered
+// within the given directories (honoring the ignore patterns)
+func (c *cli) snapshot(paths []string) map[string]fileState {
+	files := make(map[string]fileState)
+
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			// a missing file is reported when rendering
+			continue
+		}
+
+		if !info.IsDir() {
+			add(path)
+			continue
+		}
+
+		// the discovery errors are reported when rendering
+		watched, _ := composegraph.WatchedFiles(path, c.dirOptions())
+		for _, p := range watched {
+			add(p)
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/averche/docker-compose-graph/pkg/composegraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a buffer written by the watch loop & read by the test
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "compose.yaml")
+	output := filepath.Join(dir, "graph.txt")
+
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  web: {}\n"), 0o644))
+
+	var stderr syncBuffer
+
+	c := cli{stderr: &stderr, output: output}
+
+	render := func() error {
+		g, err := c.load([]string{input})
+		if err != nil {
+			return err
+		}
+		return c.write(func(w io.Writer) error {
+			return composegraph.Render(w, g, "tree", composegraph.RenderOptions{})
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- c.watch(ctx, []string{input}, 10*time.Millisecond, render) }()
+
+	contains := func(path, text string) func() bool {
+		return func() bool {
+			b, _ := os.ReadFile(path)
+			return strings.Contains(string(b), text)
+		}
+	}
+
+	require.Eventually(t, contains(output, "web"), time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  web:\n    depends_on: [db]\n  db: {}\n"), 0o644))
+	require.Eventually(t, contains(output, "db (started)"), time.Second, 10*time.Millisecond)
+
+	// the errors are printed & the last good output is kept
+	require.NoError(t, os.WriteFile(input, []byte("services: [\n"), 0o644))
+	require.Eventually(t, func() bool { return strings.Contains(stderr.String(), "Error :: could not parse") }, time.Second, 10*time.Millisecond)
+	assert.True(t, contains(output, "db (started)")())
+
+	require.NoError(t, os.WriteFile(input, []byte("services:\n  api: {}\n"), 0o644))
+	require.Eventually(t, contains(output, "api"), time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRunWatchUsage(t *testing.T) {
+	code, _, stderr := runCLI(t, "graph", "-watch", "examples/simple.yaml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "-watch requires an output file")
+
+	code, _, stderr = runCLI(t, "graph", "-watch", "-o", filepath.Join(t.TempDir(), "graph.dot"), "-")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "stdin ('-') cannot be watched")
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"compose.yaml", "notes.txt", "node_modules/lib/compose.yaml", "vendor/compose.yaml", "api/compose.yaml"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("/vendor\n"), 0o644))
+
+	c := cli{recursive: true, ignore: stringList{"node_modules/"}}
+
+	// the ignored directories are not watched
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "compose.yaml"),
+		filepath.Join(dir, ".gitignore"),
+		filepath.Join(dir, "api", "compose.yaml"),
+	}, slices.Collect(maps.Keys(c.snapshot([]string{dir}))))
+}